@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"jaytaylorcom/html2text"
+	"strconv"
+)
+
+// Struct for something that can be done to a single toot.
+type tootAction struct {
+	// The API call to make.
+	Call func(*mastodon.Client, context.Context, string) (mastodon.Status, error)
+	// Past tense for the confirmation, e.g. "Favorited".
+	Done string
+	// Whether the state the server sent back is what we asked for.
+	Confirmed func(mastodon.Status) bool
+}
+
+// Actions available from the REPL, by command.
+var tootActions = map[string]tootAction{
+	"fav": {
+		Call:      (*mastodon.Client).Favourite,
+		Done:      "Favorited",
+		Confirmed: func(toot mastodon.Status) bool { return toot.Favourited },
+	},
+	"unfav": {
+		Call:      (*mastodon.Client).Unfavourite,
+		Done:      "Unfavorited",
+		Confirmed: func(toot mastodon.Status) bool { return !toot.Favourited },
+	},
+	"boost": {
+		Call:      (*mastodon.Client).Reblog,
+		Done:      "Boosted",
+		Confirmed: func(toot mastodon.Status) bool { return toot.Reblogged },
+	},
+	"unboost": {
+		Call:      (*mastodon.Client).Unreblog,
+		Done:      "Unboosted",
+		Confirmed: func(toot mastodon.Status) bool { return !toot.Reblogged },
+	},
+	"bookmark": {
+		Call:      (*mastodon.Client).Bookmark,
+		Done:      "Bookmarked",
+		Confirmed: func(toot mastodon.Status) bool { return toot.Bookmarked },
+	},
+	"unbookmark": {
+		Call:      (*mastodon.Client).Unbookmark,
+		Done:      "Unbookmarked",
+		Confirmed: func(toot mastodon.Status) bool { return !toot.Bookmarked },
+	},
+}
+
+// Function to find a shown toot by its ID in this app. Boosts give the boosted toot.
+func findToot(timelines map[string]*timelineState, clientID int) *mastodon.Status {
+	for _, state := range timelines {
+		for i := range state.Toots {
+			if state.Toots[i].ClientID == clientID {
+				if state.Toots[i].Reblog != nil {
+					return state.Toots[i].Reblog
+				}
+				return &state.Toots[i]
+			}
+		}
+		for i := range state.Notes {
+			if state.Notes[i].Status != nil && state.Notes[i].Status.ClientID == clientID {
+				return state.Notes[i].Status
+			}
+		}
+	}
+	return nil
+}
+
+// Function to get the toot ID from the command arguments or by prompting.
+func tootIDFromArgs(args []string) int {
+	if len(args) == 0 {
+		return getTootID()
+	}
+
+	// Validate that it's an integer.
+	inputInt, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Printf("%v is not a valid integer! You must enter a valid ID...\n", args[0])
+		return 0
+	}
+	return inputInt
+}
+
+// Function to pick a shown toot from the command arguments or by prompting.
+// It says why when there isn't one and returns nil.
+func selectToot(timelines map[string]*timelineState, args []string) *mastodon.Status {
+	tootSelection := tootIDFromArgs(args)
+	if tootSelection == 0 {
+		return nil
+	}
+	toot := findToot(timelines, tootSelection)
+	if toot == nil {
+		fmt.Printf("No toot with ID %v in the local database!\n", tootSelection)
+	}
+	return toot
+}
+
+// Function to run an action on a toot, update it in place and report what the server says.
+func runTootAction(ctx context.Context, client *mastodon.Client, action tootAction, toot *mastodon.Status) {
+	updated, err := action.Call(client, ctx, toot.ID)
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	// Keep what's on screen in line with the server.
+	toot.Favourited = updated.Favourited
+	toot.Reblogged = updated.Reblogged
+	toot.Bookmarked = updated.Bookmarked
+	toot.FavouritesCount = updated.FavouritesCount
+	toot.ReblogsCount = updated.ReblogsCount
+
+	// Parse the toot content to plaintext.
+	markdown, err := html2text.FromString(toot.Content)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if !action.Confirmed(updated) {
+		fmt.Printf("The instance accepted the request but the toot's state didn't change: %v\n", markdown)
+	} else {
+		fmt.Printf("%v: %v\n", action.Done, markdown)
+	}
+	fmt.Printf("~=: ID: %v\tFavs: %v\tBoosts: %v :=~\n\n", toot.ClientID, toot.FavouritesCount, toot.ReblogsCount)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scopes requested for the application.
+const appScopes = "read write follow"
+
+// Function to write the configuration to disk.
+func writeConfig(path string, configInfo Config) {
+	err := saveConfig(path, configInfo)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(31)
+	}
+}
+
+// Function to write the configuration to disk, returning any error.
+func saveConfig(path string, configInfo Config) error {
+	config, err := json.MarshalIndent(configInfo, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	// The token is a secret, so keep the file private.
+	return ioutil.WriteFile(path, append(config, '\n'), 0600)
+}
+
+// Function to walk the user through logging in and saving the token.
+func login(configPath string, accountName string, storeName string) {
+	_, err := loginAccount(context.Background(), stdin, configPath, accountName, storeName)
+	if err != nil {
+		printError(err)
+		os.Exit(28)
+	}
+}
+
+// Function to log in with answers read from in and save the account to the config.
+func loginAccount(ctx context.Context, in *bufio.Reader, configPath string, accountName string, storeName string) (ClientConfig, error) {
+	// Prompt the user for their instance.
+	fmt.Printf("\nEnter your instance URL (e.g. https://mastodon.social).\n")
+	fmt.Print("> ")
+	instance, err := in.ReadString('\n')
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	instance, err = normalizeInstance(instance)
+	if err != nil {
+		return ClientConfig{}, err
+	}
+
+	// Register the app and send the user off to authorize it.
+	client := mastodon.NewClient(instance, "")
+	app, err := client.RegisterApp(ctx, "GoToot", "https://github.com/JFFail/GoToot", appScopes)
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	fmt.Printf("\nOpen this URL in your browser and authorize GoToot:\n%v\n", client.AuthorizeURL(app, appScopes))
+
+	// Get the code the instance displayed.
+	fmt.Printf("\nEnter the authorization code.\n")
+	fmt.Print("> ")
+	code, err := in.ReadString('\n')
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	code = strings.TrimSpace(code)
+
+	// Trade it for a token and make sure it works.
+	token, err := client.ExchangeCode(ctx, app, code, appScopes)
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	client.Token = token.AccessToken
+	currentUser, err := client.VerifyCredentials(ctx)
+	if err != nil {
+		return ClientConfig{}, err
+	}
+
+	// Keep any accounts that are already configured.
+	var configInfo Config
+	if _, err := os.Stat(configPath); err == nil {
+		configInfo = loadConfig(configPath)
+	} else {
+		err = os.MkdirAll(filepath.Dir(configPath), 0700)
+		if err != nil {
+			return ClientConfig{}, err
+		}
+	}
+
+	// Save everything for next time.
+	if accountName == "" {
+		accountName = defaultAccountName
+	}
+	if storeName != "" {
+		configInfo.CredentialStore = storeName
+	}
+	// The default visibility is left to the instance so later changes there are picked up.
+	account := ClientConfig{
+		Name:         accountName,
+		Token:        token.AccessToken,
+		Instance:     instance,
+		ClientID:     app.ClientID,
+		ClientSecret: app.ClientSecret,
+	}
+
+	// Move the token into the credential store if there is one.
+	store, err := openCredentialStore(configInfo, configPath)
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	if store != nil {
+		err = store.Set(credentialKey(account), account.Token)
+		if err != nil {
+			return ClientConfig{}, err
+		}
+		account.Token = ""
+	}
+
+	configInfo.setAccount(account)
+	err = saveConfig(configPath, configInfo)
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	fmt.Printf("Logged in as %v, saved account %v to %v\n", currentUser.Acct, accountName, configPath)
+	return account, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Function to start a stand-in instance that lets any code log in.
+func newLoginServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/apps", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"1","client_id":"cid","client_secret":"csecret"}`))
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"tok","token_type":"Bearer"}`))
+	})
+	mux.HandleFunc("/api/v1/accounts/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":"1","acct":"someone","source":{"privacy":"unlisted"}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoginAccountWritesConfig(t *testing.T) {
+	server := newLoginServer(t)
+	configPath := filepath.Join(t.TempDir(), "gototot", "client.json")
+	in := bufio.NewReader(strings.NewReader(server.URL + "/\ncode\n"))
+
+	account, err := loginAccount(context.Background(), in, configPath, "work", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Name != "work" || account.Token != "tok" || account.Instance != server.URL || account.ClientID != "cid" {
+		t.Errorf("got %+v", account)
+	}
+
+	// The instance's default visibility isn't copied, so changes there still apply.
+	if account.DefaultVisibility != "" {
+		t.Errorf("default visibility saved as %q", account.DefaultVisibility)
+	}
+
+	// The config is private and holds the new account as the default.
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("config mode is %v, want 0600", info.Mode().Perm())
+	}
+	configInfo := loadConfig(configPath)
+	if configInfo.DefaultAccount != "work" {
+		t.Errorf("default account is %q, want work", configInfo.DefaultAccount)
+	}
+	saved, found := configInfo.account("work")
+	if !found || saved != account {
+		t.Errorf("saved %+v, want %+v", saved, account)
+	}
+}
+
+func TestLoginAccountKeepsOtherAccounts(t *testing.T) {
+	server := newLoginServer(t)
+	configPath := filepath.Join(t.TempDir(), "client.json")
+	existing := Config{Accounts: []ClientConfig{{Name: "personal", Token: "old", Instance: "https://example.com"}}, DefaultAccount: "personal"}
+	err := saveConfig(configPath, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := bufio.NewReader(strings.NewReader(server.URL + "\ncode\n"))
+	_, err = loginAccount(context.Background(), in, configPath, "work", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configInfo := loadConfig(configPath)
+	if len(configInfo.Accounts) != 2 || configInfo.DefaultAccount != "personal" {
+		t.Errorf("got %+v", configInfo)
+	}
+}
+
+func TestLoginAccountKeepsImplicitDefault(t *testing.T) {
+	server := newLoginServer(t)
+
+	// Older single-account files and lists without default_account both mean the first account.
+	configs := map[string]string{
+		"legacy": `{"access_token": "old", "instance": "https://example.com"}`,
+		"list":   `{"accounts": [{"name": "personal", "access_token": "old", "instance": "https://example.com"}]}`,
+	}
+	wantDefault := map[string]string{"legacy": defaultAccountName, "list": "personal"}
+	for name, config := range configs {
+		configPath := filepath.Join(t.TempDir(), "client.json")
+		err := os.WriteFile(configPath, []byte(config), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		in := bufio.NewReader(strings.NewReader(server.URL + "\ncode\n"))
+		_, err = loginAccount(context.Background(), in, configPath, "work", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		configInfo := loadConfig(configPath)
+		if len(configInfo.Accounts) != 2 || configInfo.DefaultAccount != wantDefault[name] {
+			t.Errorf("%v: got default %q with %v accounts, want %q", name, configInfo.DefaultAccount, len(configInfo.Accounts), wantDefault[name])
+		}
+	}
+}
+
+func TestLoginAccountBadInstance(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "client.json")
+	in := bufio.NewReader(strings.NewReader("ftp://example.com\n"))
+
+	_, err := loginAccount(context.Background(), in, configPath, "", "")
+	if err == nil {
+		t.Fatal("expected an error for a non-http instance")
+	}
+	if _, statErr := os.Stat(configPath); statErr == nil {
+		t.Error("config was written after a failed login")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Exit codes for single commands, so scripts can tell failures apart.
+const (
+	// The command or its arguments were wrong.
+	exitUsage = 40
+	// The instance refused it, e.g. the toot was too long.
+	exitRejected = 41
+	// The token was refused.
+	exitAuth = 42
+	// The status doesn't exist.
+	exitNotFound = 43
+	// Anything else, like the instance being unreachable.
+	exitFailed = 44
+)
+
+// Usage for the single commands.
+const cliUsage = `Usage:
+  GoToot [--account name] post [--visibility v] [--lang code] [--sensitive] [--at time] [--cw text] [--reply-to status-id] "text"
+  GoToot [--account name] post --stdin [options]
+  GoToot [--account name] timeline home|local [--limit n] [--format text|json|ndjson|template] [--template text]
+  GoToot [--account name] notifications [--limit n] [--format text|json|ndjson|template] [--template text]
+  GoToot [--account name] fav|unfav|boost|unboost|bookmark|unbookmark <status-id>`
+
+// Function to work out the exit code for an error.
+func exitCode(err error) int {
+	var apiErr *mastodon.APIError
+	if !errors.As(err, &apiErr) {
+		return exitFailed
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return exitAuth
+	case http.StatusNotFound:
+		return exitNotFound
+	case http.StatusUnprocessableEntity:
+		return exitRejected
+	default:
+		return exitFailed
+	}
+}
+
+// Function to report a failed command and return its exit code.
+func commandFailed(err error) int {
+	writeError(os.Stderr, err)
+	return exitCode(err)
+}
+
+// Function to report bad arguments and return the usage exit code.
+func usageFailed(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	fmt.Fprintln(os.Stderr, cliUsage)
+	return exitUsage
+}
+
+// Function to run one command from the command line, returning the exit code.
+func runCommand(ctx context.Context, account ClientConfig, args []string, pageSize int, output outputFormat) int {
+	switch command := strings.ToLower(args[0]); command {
+	case "post":
+		return commandPost(ctx, account, args[1:])
+	case "timeline":
+		return commandTimeline(ctx, account, args[1:], pageSize, output)
+	case "notifications", "notes":
+		return commandTimeline(ctx, account, append([]string{"notes"}, args[1:]...), pageSize, output)
+	case "fav", "unfav", "boost", "unboost", "bookmark", "unbookmark":
+		return commandAction(ctx, account, command, args[1:])
+	case "help":
+		fmt.Println(cliUsage)
+		return 0
+	default:
+		return usageFailed("Unknown command %v.", args[0])
+	}
+}
+
+// Struct for the options post takes on top of the compose ones.
+type postOptions struct {
+	composeOptions
+	Stdin       bool
+	SpoilerText string
+	ReplyTo     string
+}
+
+// Function to split the post options from the words of the text, wherever they are.
+func parsePostOptions(args []string) (postOptions, string, error) {
+	var options postOptions
+	var at string
+	flags := composeFlagSet("post", &options.composeOptions, &at)
+	flags.BoolVar(&options.Stdin, "stdin", false, "read the text from stdin")
+	flags.StringVar(&options.SpoilerText, "cw", "", "content warning")
+	flags.StringVar(&options.ReplyTo, "reply-to", "", "ID of the status to reply to")
+	words, err := parseInterspersed(flags, args)
+	if err != nil {
+		return options, "", err
+	}
+
+	err = options.finish(at)
+	return options, strings.Join(words, " "), err
+}
+
+// Function to post a toot from the arguments or stdin.
+func commandPost(ctx context.Context, account ClientConfig, args []string) int {
+	options, text, err := parsePostOptions(args)
+	if err != nil {
+		return usageFailed("%v", err)
+	}
+
+	// Get the text from one place or the other.
+	if options.Stdin {
+		if text != "" {
+			return usageFailed("Give the text as an argument or with --stdin, not both.")
+		}
+		input, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return commandFailed(err)
+		}
+		text = strings.TrimRight(string(input), "\n")
+	}
+	if strings.TrimSpace(text) == "" {
+		return usageFailed("There's nothing to post.")
+	}
+
+	session, err := connect(ctx, account)
+	if err != nil {
+		return commandFailed(err)
+	}
+
+	// Check the length before the instance does.
+	length := countCharacters(text, session.Limits) + countGraphemes(options.SpoilerText)
+	if length > session.Limits.MaxCharacters {
+		fmt.Fprintf(os.Stderr, "That toot is %v characters and the limit is %v!\n", length, session.Limits.MaxCharacters)
+		return exitRejected
+	}
+	params := options.apply(mastodon.StatusParams{
+		Status:      text,
+		InReplyToID: options.ReplyTo,
+		SpoilerText: options.SpoilerText,
+		Visibility:  session.defaultVisibility(),
+	})
+
+	// Post it or schedule it, then print its ID for whatever runs next.
+	if !options.ScheduledAt.IsZero() {
+		scheduled, err := session.Client.ScheduleStatus(ctx, params, options.ScheduledAt)
+		if err != nil {
+			return commandFailed(err)
+		}
+		fmt.Println(scheduled.ID)
+		return 0
+	}
+	posted, err := session.Client.PostStatus(ctx, params)
+	if err != nil {
+		return commandFailed(err)
+	}
+	fmt.Println(posted.ID)
+	return 0
+}
+
+// Function to print one page of a timeline or the notifications.
+func commandTimeline(ctx context.Context, account ClientConfig, args []string, pageSize int, output outputFormat) int {
+	// The timeline comes first, then the options.
+	name := "home"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = timelineName(args[0])
+		args = args[1:]
+	}
+	switch name {
+	case "home", "local", "notes":
+	case "notifications":
+		name = "notes"
+	default:
+		return usageFailed("Unknown timeline %v.", name)
+	}
+
+	flags := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	limit := flags.Int("limit", pageSize, "how many to show")
+	format := flags.String("format", "", "text, json, ndjson or template")
+	templateText := flags.String("template", "", "Go template for each item")
+	err := flags.Parse(args)
+	if err != nil {
+		return usageFailed("%v", err)
+	}
+	output, err = chooseOutputFormat(*format, *templateText, output)
+	if err != nil {
+		return usageFailed("%v", err)
+	}
+	if *limit < 1 || flags.NArg() > 0 {
+		return usageFailed("Give one timeline and a --limit of at least 1.")
+	}
+
+	session, err := connect(ctx, account)
+	if err != nil {
+		return commandFailed(err)
+	}
+	toots, notes, _, err := fetchPage(ctx, session.Client, name, mastodon.PageParams{Limit: *limit})
+	if err != nil {
+		return commandFailed(err)
+	}
+	toots, tootCounter := assignIndexToots(toots, 0)
+	notes, _ = assignIndexNotes(notes, tootCounter)
+	if name == "notes" {
+		output.printNotifications(notes)
+	} else {
+		output.printToots(toots)
+	}
+	return 0
+}
+
+// Function to fav, boost or bookmark a status by its ID on the instance.
+func commandAction(ctx context.Context, account ClientConfig, command string, args []string) int {
+	if len(args) != 1 {
+		return usageFailed("%v takes one status ID.", command)
+	}
+
+	session, err := connect(ctx, account)
+	if err != nil {
+		return commandFailed(err)
+	}
+	action := tootActions[command]
+	updated, err := action.Call(session.Client, ctx, args[0])
+	if err != nil {
+		return commandFailed(err)
+	}
+	if !action.Confirmed(updated) {
+		fmt.Fprintf(os.Stderr, "The instance accepted the request but the status %v didn't change.\n", updated.ID)
+		return exitFailed
+	}
+	fmt.Printf("%v %v\n", action.Done, updated.ID)
+	return 0
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParsePostOptions(t *testing.T) {
+	tests := []struct {
+		args []string
+		want postOptions
+		text string
+	}{
+		{[]string{"hi", "there"}, postOptions{}, "hi there"},
+		{[]string{"--visibility=direct", "hi"}, postOptions{composeOptions: composeOptions{Visibility: "direct"}}, "hi"},
+		{[]string{"hi", "--visibility=direct"}, postOptions{composeOptions: composeOptions{Visibility: "direct"}}, "hi"},
+		{[]string{"hi", "--cw", "spoilers", "there", "--reply-to", "109"}, postOptions{SpoilerText: "spoilers", ReplyTo: "109"}, "hi there"},
+		{[]string{"--stdin", "--lang", "de"}, postOptions{composeOptions: composeOptions{Language: "de"}, Stdin: true}, ""},
+		{[]string{"hi", "--", "--visibility=direct"}, postOptions{}, "hi --visibility=direct"},
+	}
+	for _, test := range tests {
+		options, text, err := parsePostOptions(test.args)
+		if err != nil {
+			t.Errorf("%q: %v", test.args, err)
+			continue
+		}
+		if options != test.want || text != test.text {
+			t.Errorf("%q: got %+v %q, want %+v %q", test.args, options, text, test.want, test.text)
+		}
+	}
+}
+
+func TestParsePostOptionsErrors(t *testing.T) {
+	for _, args := range [][]string{{"hi", "--visibility", "everyone"}, {"hi", "--cw"}, {"--nope", "hi"}} {
+		_, _, err := parsePostOptions(args)
+		if err == nil {
+			t.Errorf("%q: expected an error", args)
+		}
+	}
+}
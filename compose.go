@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Reader shared by every prompt so buffered input isn't lost between them.
+var stdin = bufio.NewReader(os.Stdin)
+
+// Set when running a single command, so anything that would prompt fails instead of waiting.
+var noPrompt bool
+
+// Returned by prompts when noPrompt is set.
+var errNoPrompt = errors.New("can't prompt for input when running a single command")
+
+// Function to print a prompt and read a line, without the newline.
+func promptLine(prompt string) (string, error) {
+	if noPrompt {
+		return "", errNoPrompt
+	}
+	fmt.Printf("\n%v\n", prompt)
+	fmt.Print("> ")
+	text, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(text, "\r\n"), nil
+}
+
+// Function to open the user's editor on a temp file and return what they saved.
+func editInEditor(initial string) (string, error) {
+	file, err := ioutil.TempFile("", "gototot-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(initial)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	// $VISUAL wins over $EDITOR, and either can include arguments.
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), file.Name())
+	command := exec.Command(args[0], args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err = command.Run()
+	if err != nil {
+		return "", fmt.Errorf("%v: %v", editor, err)
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(edited), "\n"), nil
+}
+
+// Function to read toot text, which can run over several lines or come from the editor.
+func readTootText(current string) (string, error) {
+	// Explain how to finish.
+	if current != "" {
+		fmt.Printf("\nPress enter to keep the text, type new text, or enter :e to edit it in $EDITOR.\n")
+	} else {
+		fmt.Printf("\nEnter your toot. End a line with \\ to keep going, or enter :e to use $EDITOR.\n")
+	}
+
+	var lines []string
+	for {
+		fmt.Print("> ")
+		line, err := stdin.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// Switch to the editor, keeping anything typed so far.
+		if line == ":e" {
+			initial := current
+			if len(lines) > 0 {
+				initial = strings.Join(lines, "\n")
+			}
+			return editInEditor(initial)
+		}
+
+		// A trailing backslash means there's more to come.
+		if strings.HasSuffix(line, "\\") {
+			lines = append(lines, strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		lines = append(lines, line)
+		break
+	}
+
+	text := strings.Join(lines, "\n")
+	if text == "" {
+		return current, nil
+	}
+	return text, nil
+}
+
+// Function to get toot content that fits the instance's limit. Mentions and the CW count towards it.
+func getTootContent(limits composeLimits, prefix string, spoiler string, current string) string {
+	for {
+		text, err := readTootText(current)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(9)
+		}
+
+		// Verify we're within the length limit.
+		length := countCharacters(strings.TrimSpace(prefix+" "+text), limits) + countGraphemes(spoiler)
+		if length > limits.MaxCharacters {
+			fmt.Printf("That toot is %v characters and the limit is %v! Try again...\n", length, limits.MaxCharacters)
+			current = text
+			continue
+		}
+		fmt.Printf("(%v/%v characters)\n", length, limits.MaxCharacters)
+		return text
+	}
+}
+
+// Struct for the options given to the compose commands.
+type composeOptions struct {
+	Visibility string
+	Language   string
+	Sensitive  bool
+	// When to post it. Zero posts it now.
+	ScheduledAt time.Time
+}
+
+// Function to build the flags shared by every compose command, storing them in options and at.
+func composeFlagSet(name string, options *composeOptions, at *string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.StringVar(&options.Visibility, "visibility", "", "public, unlisted, private or direct")
+	flags.StringVar(&options.Language, "lang", "", "language code, e.g. de")
+	flags.BoolVar(&options.Sensitive, "sensitive", false, "mark media as sensitive")
+	flags.StringVar(at, "at", "", "when to post it, e.g. +2h or 2006-01-02T15:04")
+	return flags
+}
+
+// Function to check the parsed compose options and work out when to post.
+func (o *composeOptions) finish(at string) error {
+	if at != "" {
+		scheduledAt, err := parseScheduleTime(at, time.Now())
+		if err != nil {
+			return err
+		}
+		o.ScheduledAt = scheduledAt
+	}
+
+	// Catch typos before they reach the instance.
+	switch o.Visibility {
+	case "", "public", "unlisted", "private", "direct":
+	default:
+		return fmt.Errorf("%v is not a valid visibility, use public, unlisted, private or direct", o.Visibility)
+	}
+	return nil
+}
+
+// Function to split the compose options like --visibility unlisted from the other arguments.
+func parseComposeOptions(args []string) (composeOptions, []string, error) {
+	var options composeOptions
+	var at string
+	flags := composeFlagSet("compose", &options, &at)
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return options, positional, err
+	}
+
+	err = options.finish(at)
+	return options, positional, err
+}
+
+// Function to parse flags wherever they are in the arguments, returning the rest in order.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	// The flag package stops at the first positional argument, so keep it and carry on after it.
+	remaining := args
+	for len(remaining) > 0 {
+		err := flags.Parse(remaining)
+		if err != nil {
+			return positional, err
+		}
+		rest := flags.Args()
+
+		// Everything after a -- is positional.
+		consumed := len(remaining) - len(rest)
+		if consumed > 0 && remaining[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		remaining = rest[1:]
+	}
+	return positional, nil
+}
+
+// Function to apply the compose options to a draft.
+func (o composeOptions) apply(params mastodon.StatusParams) mastodon.StatusParams {
+	if o.Visibility != "" {
+		params.Visibility = o.Visibility
+	}
+	if o.Language != "" {
+		params.Language = o.Language
+	}
+	if o.Sensitive {
+		params.Sensitive = true
+	}
+	return params
+}
+
+// Function to describe where and when a draft is going before it's written.
+func describeDraft(params mastodon.StatusParams, at time.Time) string {
+	description := fmt.Sprintf("Posting to |%v|", params.Visibility)
+	if params.Language != "" {
+		description += fmt.Sprintf(" in %v", params.Language)
+	}
+	if params.Sensitive {
+		description += " with sensitive media"
+	}
+	if !at.IsZero() {
+		description += fmt.Sprintf(" at %v", prettyTime(at))
+	}
+	return description
+}
+
+// Function to post a finished draft, or leave it with the instance for later if it has a time.
+func sendDraft(ctx context.Context, session Session, params mastodon.StatusParams, at time.Time, noun string) {
+	if !at.IsZero() {
+		scheduleDraft(ctx, session.Client, params, at)
+		return
+	}
+	posted, err := session.Client.PostStatus(ctx, params)
+	if err != nil {
+		printError(err)
+		return
+	}
+	fmt.Printf("Successfully posted %v: %v\n\n", noun, posted.ID)
+}
+
+// Function to compose and post a new toot.
+func composeToot(ctx context.Context, session Session, options composeOptions, withCW bool) {
+	params := options.apply(mastodon.StatusParams{Visibility: session.defaultVisibility()})
+
+	// Prompt the user for their spoiler text.
+	if withCW {
+		cwText, err := promptLine("Enter your spoiler text.")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		params.SpoilerText = cwText
+	}
+
+	// Prompt the user for their text.
+	fmt.Printf("\n%v\n", describeDraft(params, options.ScheduledAt))
+	params.Status = getTootContent(session.Limits, "", params.SpoilerText, "")
+	params.MediaIDs, params.Poll = attachMedia(ctx, session)
+
+	sendDraft(ctx, session, params, options.ScheduledAt, "toot")
+}
+
+// Function to build the @mentions for a reply, leaving out our own account.
+func replyMentions(toot mastodon.Status, self string) []string {
+	var mentions []string
+	seen := map[string]bool{strings.ToLower(self): true}
+
+	// The author goes first, then everyone they mentioned.
+	accts := []string{toot.Account.Acct}
+	for _, mention := range toot.Mentions {
+		accts = append(accts, mention.Acct)
+	}
+	for _, acct := range accts {
+		if acct == "" || seen[strings.ToLower(acct)] {
+			continue
+		}
+		seen[strings.ToLower(acct)] = true
+		mentions = append(mentions, "@"+acct)
+	}
+	return mentions
+}
+
+// Function to compose and post a reply to a toot.
+func replyToToot(ctx context.Context, session Session, toot *mastodon.Status, options composeOptions) {
+	// Replies keep the parent's audience and CW unless told otherwise.
+	params := options.apply(mastodon.StatusParams{
+		InReplyToID: toot.ID,
+		Visibility:  toot.Visibility,
+		Sensitive:   toot.Sensitive,
+		SpoilerText: toot.SpoilerText,
+	})
+	mentions := strings.Join(replyMentions(*toot, session.User.Acct), " ")
+	fmt.Printf("\nReplying to %v. %v\n", toot.Account.Acct, describeDraft(params, options.ScheduledAt))
+
+	// Offer to change the CW if there was one.
+	if params.SpoilerText != "" {
+		cwText, err := promptLine(fmt.Sprintf("CW: %v\nPress enter to keep it, type a new one, or - to remove it.", params.SpoilerText))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if cwText == "-" {
+			params.Sensitive = false
+			params.SpoilerText = ""
+		} else if cwText != "" {
+			params.SpoilerText = cwText
+		}
+	}
+
+	// Prompt the user for their text, after the mentions.
+	if mentions != "" {
+		fmt.Printf("\nMentioning: %v", mentions)
+	}
+	text := getTootContent(session.Limits, mentions, params.SpoilerText, "")
+	params.Status = strings.TrimSpace(mentions + " " + text)
+	params.MediaIDs, params.Poll = attachMedia(ctx, session)
+
+	sendDraft(ctx, session, params, options.ScheduledAt, "reply")
+}
+
+// Function to ask a yes or no question, defaulting to no.
+func confirm(question string) bool {
+	answer, err := promptLine(fmt.Sprintf("%v [y/N]", question))
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// Function to let the user change the text and CW of a draft, keeping whatever they leave blank.
+func editDraft(draft mastodon.StatusParams, limits composeLimits) (mastodon.StatusParams, error) {
+	fmt.Printf("\nCurrent text to |%v|:\n%v\n", draft.Visibility, draft.Status)
+
+	// Offer to change the CW.
+	cwPrompt := "No CW. Press enter to leave it off, or type one."
+	if draft.SpoilerText != "" {
+		cwPrompt = fmt.Sprintf("CW: %v\nPress enter to keep it, type a new one, or - to remove it.", draft.SpoilerText)
+	}
+	cwText, err := promptLine(cwPrompt)
+	if err != nil {
+		return draft, err
+	}
+	if cwText == "-" {
+		draft.Sensitive = false
+		draft.SpoilerText = ""
+	} else if cwText != "" {
+		draft.Sensitive = true
+		draft.SpoilerText = cwText
+	}
+
+	// Then the text itself.
+	draft.Status = getTootContent(limits, "", draft.SpoilerText, draft.Status)
+	return draft, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseComposeOptions(t *testing.T) {
+	tests := []struct {
+		args       []string
+		want       composeOptions
+		positional []string
+	}{
+		{nil, composeOptions{}, nil},
+		{[]string{"--visibility", "unlisted"}, composeOptions{Visibility: "unlisted"}, nil},
+		{[]string{"--visibility=unlisted", "--lang", "de"}, composeOptions{Visibility: "unlisted", Language: "de"}, nil},
+		{[]string{"12", "--visibility", "direct", "--sensitive"}, composeOptions{Visibility: "direct", Sensitive: true}, []string{"12"}},
+		{[]string{"--sensitive", "12", "--lang=de", "13"}, composeOptions{Language: "de", Sensitive: true}, []string{"12", "13"}},
+		{[]string{"12", "--", "--lang"}, composeOptions{}, []string{"12", "--lang"}},
+	}
+	for _, test := range tests {
+		options, positional, err := parseComposeOptions(test.args)
+		if err != nil {
+			t.Errorf("%q: %v", test.args, err)
+			continue
+		}
+		if options != test.want || !reflect.DeepEqual(positional, test.positional) {
+			t.Errorf("%q: got %+v %q, want %+v %q", test.args, options, positional, test.want, test.positional)
+		}
+	}
+}
+
+func TestParseComposeOptionsAt(t *testing.T) {
+	for _, args := range [][]string{{"--at", "+2h"}, {"--at=+2h"}} {
+		options, _, err := parseComposeOptions(args)
+		if err != nil {
+			t.Errorf("%q: %v", args, err)
+			continue
+		}
+		if until := time.Until(options.ScheduledAt); until < 119*time.Minute || until > 2*time.Hour {
+			t.Errorf("%q: scheduled for %v from now, want 2h", args, until)
+		}
+	}
+}
+
+func TestParseComposeOptionsErrors(t *testing.T) {
+	for _, args := range [][]string{{"--visibility"}, {"--visibility", "everyone"}, {"--nope"}} {
+		_, _, err := parseComposeOptions(args)
+		if err == nil {
+			t.Errorf("%q: expected an error", args)
+		}
+	}
+}
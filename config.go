@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Name given to an account when none is specified.
+const defaultAccountName = "default"
+
+// Name of the directory and file holding the config.
+const configDirName = "gototot"
+const configFileName = "client.json"
+
+// Struct for a single account in the .json configuration.
+type ClientConfig struct {
+	Name              string `json:"name"`
+	Token             string `json:"access_token,omitempty"`
+	Instance          string `json:"instance"`
+	DefaultVisibility string `json:"default_visibility,omitempty"`
+	ClientID          string `json:"client_id,omitempty"`
+	ClientSecret      string `json:"client_secret,omitempty"`
+}
+
+// Struct for the .json configuration file.
+type Config struct {
+	DefaultAccount  string         `json:"default_account,omitempty"`
+	CredentialStore string         `json:"credential_store,omitempty"`
+	CredentialFile  string         `json:"credential_file,omitempty"`
+	PageSize        int            `json:"page_size,omitempty"`
+	Accounts        []ClientConfig `json:"accounts"`
+
+	// Go templates for printing each kind of toot and notification.
+	Templates map[string]string `json:"templates,omitempty"`
+
+	// Colours for the terminal, by name.
+	Theme  string           `json:"theme,omitempty"`
+	Themes map[string]Theme `json:"themes,omitempty"`
+
+	// Older files hold a single account at the top level.
+	Token    string `json:"access_token,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Function to list the places the config may live, in order of preference.
+func configCandidates(flagPath string) []string {
+	// An explicit path wins outright.
+	if flagPath != "" {
+		return []string{flagPath}
+	}
+	if envPath := os.Getenv("GOTOOT_CONFIG"); envPath != "" {
+		return []string{envPath}
+	}
+
+	var candidates []string
+	if xdgHome := os.Getenv("XDG_CONFIG_HOME"); xdgHome != "" {
+		candidates = append(candidates, filepath.Join(xdgHome, configDirName, configFileName))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".config", configDirName, configFileName))
+	}
+
+	// Older versions only looked in the working directory.
+	return append(candidates, configFileName)
+}
+
+// Function to find the config file, returning where to create it if there isn't one.
+func findConfig(flagPath string) (string, bool) {
+	candidates := configCandidates(flagPath)
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+	}
+
+	// New configs go in the first location checked.
+	return candidates[0], false
+}
+
+// Function to clean up an instance URL so the API paths can be appended.
+func normalizeInstance(instance string) (string, error) {
+	instance = strings.TrimSpace(instance)
+	if instance == "" {
+		return "", errors.New("instance is empty")
+	}
+
+	// Assume https if no scheme was given.
+	if !strings.Contains(instance, "://") {
+		instance = "https://" + instance
+	}
+
+	// Drop the trailing slash and any API path.
+	instance = strings.TrimRight(instance, "/")
+	instance = strings.TrimSuffix(instance, "/api/v1")
+
+	parsed, err := url.Parse(instance)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return "", fmt.Errorf("instance %v must be an http(s) URL", instance)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("instance %v has no host", instance)
+	}
+
+	return instance, nil
+}
+
+// Function to apply the environment overrides to the account picked at startup.
+func applyEnvOverrides(account ClientConfig) ClientConfig {
+	if token := os.Getenv("GOTOOT_TOKEN"); token != "" {
+		account.Token = token
+	}
+	if instance := os.Getenv("GOTOOT_INSTANCE"); instance != "" {
+		account.Instance = instance
+	}
+
+	// Overridden instances need the same cleanup as the file.
+	instance, err := normalizeInstance(account.Instance)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(35)
+	}
+	account.Instance = instance
+
+	return account
+}
+
+// Function to get an account ready to connect with, exiting if it can't be.
+func prepareAccount(account ClientConfig, store CredentialStore) ClientConfig {
+	// Pull the token out of the store if it isn't in the config.
+	account, err := resolveToken(store, account)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(37)
+	}
+
+	return account
+}
+
+// Function to read the configuration file.
+func loadConfig(path string) Config {
+	// Import the file with the config.
+	config, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Run \"GoToot login\" to create it.")
+		os.Exit(1)
+	}
+
+	// Create the struct with the config.
+	var configInfo Config
+	err = json.Unmarshal(config, &configInfo)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	// Turn a single-account file into a list of one.
+	if configInfo.Token != "" {
+		configInfo.Accounts = append(configInfo.Accounts, ClientConfig{
+			Name:     defaultAccountName,
+			Token:    configInfo.Token,
+			Instance: configInfo.Instance,
+		})
+		configInfo.Token = ""
+		configInfo.Instance = ""
+	}
+
+	// Without a default the first account is used, so make that explicit before any more are added.
+	if configInfo.DefaultAccount == "" && len(configInfo.Accounts) > 0 {
+		configInfo.DefaultAccount = configInfo.Accounts[0].Name
+	}
+
+	// Clean up the instances so the README's rules aren't load-bearing.
+	for i, account := range configInfo.Accounts {
+		instance, err := normalizeInstance(account.Instance)
+		if err != nil {
+			fmt.Printf("Account %v: %v\n", account.Name, err)
+			os.Exit(35)
+		}
+		configInfo.Accounts[i].Instance = instance
+	}
+
+	return configInfo
+}
+
+// Function to find an account by name, falling back to the default.
+func (c Config) account(name string) (ClientConfig, bool) {
+	if name == "" {
+		name = c.DefaultAccount
+	}
+
+	// With no name at all, use the first account.
+	if name == "" && len(c.Accounts) > 0 {
+		return c.Accounts[0], true
+	}
+
+	for _, account := range c.Accounts {
+		if account.Name == name {
+			return account, true
+		}
+	}
+	return ClientConfig{}, false
+}
+
+// Function to add an account or replace the one with the same name.
+func (c *Config) setAccount(newAccount ClientConfig) {
+	for i, account := range c.Accounts {
+		if account.Name == newAccount.Name {
+			c.Accounts[i] = newAccount
+			return
+		}
+	}
+	c.Accounts = append(c.Accounts, newAccount)
+
+	// The first account added becomes the default.
+	if c.DefaultAccount == "" {
+		c.DefaultAccount = newAccount.Name
+	}
+}
+
+// Struct for the account the REPL is currently using.
+type Session struct {
+	Account ClientConfig
+	Client  *mastodon.Client
+	User    mastodon.CredentialAccount
+	Limits  composeLimits
+}
+
+// Function to get the visibility new toots get unless told otherwise.
+func (s Session) defaultVisibility() string {
+	if s.Account.DefaultVisibility != "" {
+		return s.Account.DefaultVisibility
+	}
+	if s.User.Source.Privacy != "" {
+		return s.User.Source.Privacy
+	}
+	return "public"
+}
+
+// Function to build the prompt, showing the quota once it runs low.
+func (s Session) prompt() string {
+	limit := s.Client.RateLimit()
+	if limit.Known && limit.Remaining*10 < limit.Limit {
+		return fmt.Sprintf("[%v %v/%v]: ", s.User.Acct, limit.Remaining, limit.Limit)
+	}
+	return fmt.Sprintf("[%v]: ", s.User.Acct)
+}
+
+// Function to say who the session is logged in as.
+func (s Session) greet() {
+	fmt.Printf("Logged in as: %v\n", s.User.Acct)
+	fmt.Printf("%v statuses, last one posted on %v\n\n", s.User.StatusesCount, s.User.LastStatusAt)
+}
+
+// Function to verify an account and build a session for it.
+func connect(ctx context.Context, account ClientConfig) (Session, error) {
+	client := mastodon.NewClient(account.Instance, account.Token)
+
+	// Verify the token is valid.
+	_, err := client.VerifyAppCredentials(ctx)
+	if err != nil {
+		return Session{}, fmt.Errorf("token for %v is invalid: %w", account.Instance, err)
+	}
+
+	// Verify the user information.
+	currentUser, err := client.VerifyCredentials(ctx)
+	if err != nil {
+		return Session{}, err
+	}
+	// Find out how long toots can be, falling back to the usual limits.
+	limits := composeLimits{
+		MaxCharacters:        defaultMaxCharacters,
+		URLLength:            defaultURLLength,
+		MaxMedia:             defaultMaxMedia,
+		MaxPollOptions:       defaultMaxPollOptions,
+		PollOptionCharacters: defaultPollOptionCharacters,
+		MinPollDuration:      defaultMinPollDuration,
+		MaxPollDuration:      defaultMaxPollDuration,
+	}
+	instance, err := client.GetInstance(ctx)
+	if err == nil {
+		if instance.Configuration.Statuses.MaxCharacters > 0 {
+			limits.MaxCharacters = instance.Configuration.Statuses.MaxCharacters
+		}
+		if instance.Configuration.Statuses.CharactersReservedPerURL > 0 {
+			limits.URLLength = instance.Configuration.Statuses.CharactersReservedPerURL
+		}
+		if instance.Configuration.Statuses.MaxMediaAttachments > 0 {
+			limits.MaxMedia = instance.Configuration.Statuses.MaxMediaAttachments
+		}
+		polls := instance.Configuration.Polls
+		if polls.MaxOptions > 0 {
+			limits.MaxPollOptions = polls.MaxOptions
+		}
+		if polls.MaxCharactersPerOption > 0 {
+			limits.PollOptionCharacters = polls.MaxCharactersPerOption
+		}
+		if polls.MinExpiration > 0 {
+			limits.MinPollDuration = time.Duration(polls.MinExpiration) * time.Second
+		}
+		if polls.MaxExpiration > 0 {
+			limits.MaxPollDuration = time.Duration(polls.MaxExpiration) * time.Second
+		}
+	}
+
+	return Session{
+		Account: account,
+		Client:  client,
+		User:    currentUser,
+		Limits:  limits,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeInstance(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"mastodon.social", "https://mastodon.social"},
+		{" https://mastodon.social/ \n", "https://mastodon.social"},
+		{"https://mastodon.social/api/v1", "https://mastodon.social"},
+		{"http://127.0.0.1:8080/", "http://127.0.0.1:8080"},
+	}
+	for _, test := range tests {
+		got, err := normalizeInstance(test.in)
+		if err != nil || got != test.want {
+			t.Errorf("normalizeInstance(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+
+	_, err := normalizeInstance("ftp://example.com")
+	if err == nil || !strings.Contains(err.Error(), "must be an http(s) URL") {
+		t.Errorf("got %v for an ftp instance", err)
+	}
+	_, err = normalizeInstance("")
+	if err == nil {
+		t.Error("expected an error for an empty instance")
+	}
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv("GOTOOT_TOKEN", "envtoken")
+	t.Setenv("GOTOOT_INSTANCE", "env.example/")
+
+	account := applyEnvOverrides(ClientConfig{Name: "work", Token: "filetoken", Instance: "https://file.example"})
+	if account.Token != "envtoken" || account.Instance != "https://env.example" {
+		t.Errorf("got %+v", account)
+	}
+}
+
+func TestPrepareAccountIgnoresEnv(t *testing.T) {
+	t.Setenv("GOTOOT_TOKEN", "envtoken")
+	t.Setenv("GOTOOT_INSTANCE", "https://env.example")
+
+	// Switching accounts must not pick up the startup overrides.
+	account := prepareAccount(ClientConfig{Name: "work", Token: "filetoken", Instance: "https://file.example"}, nil)
+	if account.Token != "filetoken" || account.Instance != "https://file.example" {
+		t.Errorf("got %+v", account)
+	}
+}
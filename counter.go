@@ -0,0 +1,88 @@
+package main
+
+import (
+	"regexp"
+	"time"
+	"unicode"
+)
+
+// Limits used when Mastodon's own can't be fetched.
+const (
+	defaultMaxCharacters = 500
+	defaultURLLength     = 23
+	defaultMaxMedia      = 4
+
+	// Polls.
+	defaultMaxPollOptions       = 4
+	defaultPollOptionCharacters = 50
+	defaultMinPollDuration      = 5 * time.Minute
+	defaultMaxPollDuration      = 30 * 24 * time.Hour
+)
+
+// Every link counts as the same length no matter how long it is.
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// Mentions of remote accounts only count the username, not the domain.
+var mentionPattern = regexp.MustCompile(`(?i)(^|[^\w/])@(\w+)@[a-z0-9.\-]+[a-z0-9]`)
+
+// Struct for how long a toot is allowed to be.
+type composeLimits struct {
+	MaxCharacters int
+	URLLength     int
+	MaxMedia      int
+
+	// Polls.
+	MaxPollOptions       int
+	PollOptionCharacters int
+	MinPollDuration      time.Duration
+	MaxPollDuration      time.Duration
+}
+
+// Function to count the characters in a toot the way Mastodon does.
+func countCharacters(text string, limits composeLimits) int {
+	// Links count as a fixed length.
+	links := 0
+	text = urlPattern.ReplaceAllStringFunc(text, func(string) string {
+		links++
+		return ""
+	})
+
+	// Strip the domain off of remote mentions.
+	text = mentionPattern.ReplaceAllString(text, "$1@$2")
+
+	return countGraphemes(text) + links*limits.URLLength
+}
+
+// Function to count what a reader would see as characters, so an emoji with a skin tone counts as one.
+func countGraphemes(text string) int {
+	count := 0
+	joinNext := false
+	regionalIndicators := 0
+	for _, r := range text {
+		switch {
+		case joinNext:
+			// The character after a zero width joiner is part of the same emoji.
+			joinNext = false
+		case r == '\u200d':
+			joinNext = true
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+			// Combining marks belong to the character before them.
+		case r >= '\ufe00' && r <= '\ufe0f':
+			// So do variation selectors.
+		case r >= 0x1F3FB && r <= 0x1F3FF:
+			// And skin tone modifiers.
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			// Flags are pairs of regional indicators.
+			regionalIndicators++
+			if regionalIndicators%2 == 1 {
+				count++
+			}
+		default:
+			count++
+		}
+		if r < 0x1F1E6 || r > 0x1F1FF {
+			regionalIndicators = 0
+		}
+	}
+	return count
+}
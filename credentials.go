@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the credential store backends.
+const (
+	storePlaintext = "plaintext"
+	storeKeyring   = "keyring"
+	storeFile      = "file"
+)
+
+// Service name the tokens are filed under in the keyring.
+const keyringService = "gototot"
+
+// PBKDF2 iterations for deriving the file key from the passphrase.
+const credentialKeyIterations = 600000
+
+// Returned when a store has no token for an account.
+var errCredentialNotFound = errors.New("no stored token for account")
+
+// Interface for somewhere to keep access tokens other than the config.
+type CredentialStore interface {
+	Get(key string) (string, error)
+	Set(key string, token string) error
+}
+
+// Function to build the key a token is stored under.
+func credentialKey(account ClientConfig) string {
+	return fmt.Sprintf("%v@%v", account.Name, account.Instance)
+}
+
+// Function to open the store chosen in the config. Plaintext tokens stay in the config, so it returns nil.
+func openCredentialStore(configInfo Config, configPath string) (CredentialStore, error) {
+	switch configInfo.CredentialStore {
+	case "", storePlaintext:
+		return nil, nil
+	case storeKeyring:
+		return keyringStore{}, nil
+	case storeFile:
+		// Keep the encrypted file next to the config unless told otherwise.
+		path := configInfo.CredentialFile
+		if path == "" {
+			path = filepath.Join(filepath.Dir(configPath), "credentials.enc")
+		}
+		return &fileStore{Path: path, Passphrase: credentialPassphrase}, nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %v", configInfo.CredentialStore)
+	}
+}
+
+// Function to fill in an account's token from the store.
+func resolveToken(store CredentialStore, account ClientConfig) (ClientConfig, error) {
+	// Tokens in the config or the environment don't need the store.
+	if account.Token != "" || store == nil {
+		return account, nil
+	}
+
+	token, err := store.Get(credentialKey(account))
+	if err != nil {
+		return account, fmt.Errorf("account %v: %v", account.Name, err)
+	}
+	account.Token = token
+	return account, nil
+}
+
+// Function to get the passphrase for the encrypted file.
+func credentialPassphrase() (string, error) {
+	if passphrase := os.Getenv("GOTOOT_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if noPrompt {
+		return "", errors.New("set GOTOOT_PASSPHRASE to read the credentials file without a prompt")
+	}
+
+	// Prompt the user for it.
+	fmt.Printf("\nEnter the passphrase for your credentials file.\n")
+	fmt.Print("> ")
+	passphrase, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(passphrase, "\r\n"), nil
+}
+
+// Struct for the Secret Service keyring, reached through secret-tool.
+type keyringStore struct{}
+
+// Function to look up a token in the keyring.
+func (k keyringStore) Get(key string) (string, error) {
+	var stdout bytes.Buffer
+	command := exec.Command("secret-tool", "lookup", "service", keyringService, "account", key)
+	command.Stdout = &stdout
+	err := command.Run()
+
+	// secret-tool exits 1 with no output when nothing matches.
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && stdout.Len() == 0 {
+		return "", errCredentialNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("secret-tool lookup: %v", err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// Function to save a token in the keyring.
+func (k keyringStore) Set(key string, token string) error {
+	label := fmt.Sprintf("GoToot token for %v", key)
+	command := exec.Command("secret-tool", "store", "--label", label, "service", keyringService, "account", key)
+
+	// The secret is read from stdin so it never shows up in the process list.
+	command.Stdin = strings.NewReader(token)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("secret-tool store: %v %v", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Struct for the on-disk format of the encrypted file.
+type encryptedCredentials struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// Struct for a passphrase-encrypted file of tokens.
+type fileStore struct {
+	Path       string
+	Passphrase func() (string, error)
+
+	// The passphrase is only asked for once.
+	passphrase string
+}
+
+// Function to get the passphrase, prompting the first time.
+func (f *fileStore) getPassphrase() (string, error) {
+	if f.passphrase != "" {
+		return f.passphrase, nil
+	}
+	passphrase, err := f.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("passphrase is empty")
+	}
+	f.passphrase = passphrase
+	return passphrase, nil
+}
+
+// Function to build the cipher for a salt.
+func (f *fileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	passphrase, err := f.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, credentialKeyIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Function to decrypt all of the tokens in the file.
+func (f *fileStore) load() (map[string]string, error) {
+	tokens := make(map[string]string)
+	raw, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted encryptedCredentials
+	err = json.Unmarshal(raw, &encrypted)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := f.cipher(encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, encrypted.Nonce, encrypted.Data, nil)
+	if err != nil {
+		return nil, errors.New("could not decrypt credentials, wrong passphrase?")
+	}
+
+	err = json.Unmarshal(plain, &tokens)
+	return tokens, err
+}
+
+// Function to encrypt all of the tokens and write the file.
+func (f *fileStore) save(tokens map[string]string) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	// Every write gets a fresh salt and nonce.
+	encrypted := encryptedCredentials{Salt: make([]byte, 16)}
+	_, err = rand.Read(encrypted.Salt)
+	if err != nil {
+		return err
+	}
+	aead, err := f.cipher(encrypted.Salt)
+	if err != nil {
+		return err
+	}
+	encrypted.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(encrypted.Nonce)
+	if err != nil {
+		return err
+	}
+	encrypted.Data = aead.Seal(nil, encrypted.Nonce, plain, nil)
+
+	raw, err := json.Marshal(encrypted)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.Path, raw, 0600)
+}
+
+// Function to look up a token in the encrypted file.
+func (f *fileStore) Get(key string) (string, error) {
+	tokens, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	token, found := tokens[key]
+	if !found {
+		return "", errCredentialNotFound
+	}
+	return token, nil
+}
+
+// Function to save a token in the encrypted file.
+func (f *fileStore) Set(key string, token string) error {
+	tokens, err := f.load()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	return f.save(tokens)
+}
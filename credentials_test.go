@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Function to build a file store that never prompts.
+func newTestFileStore(path string, passphrase string) *fileStore {
+	return &fileStore{
+		Path: path,
+		Passphrase: func() (string, error) {
+			return passphrase, nil
+		},
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store := newTestFileStore(path, "hunter2")
+
+	err := store.Set("personal@https://mastodon.social", "token1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Set("work@https://fosstodon.org", "token2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh store with the same passphrase reads both back.
+	reopened := newTestFileStore(path, "hunter2")
+	token, err := reopened.Get("personal@https://mastodon.social")
+	if err != nil || token != "token1" {
+		t.Errorf("got %q, %v, want token1", token, err)
+	}
+	token, err = reopened.Get("work@https://fosstodon.org")
+	if err != nil || token != "token2" {
+		t.Errorf("got %q, %v, want token2", token, err)
+	}
+
+	// The tokens are not stored in the clear.
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "token1") {
+		t.Error("token found in plaintext in the file")
+	}
+}
+
+func TestFileStoreMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	err := newTestFileStore(path, "hunter2").Set("key", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode is %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestFileStoreWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	err := newTestFileStore(path, "hunter2").Set("key", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = newTestFileStore(path, "wrong").Get("key")
+	if err == nil || errors.Is(err, errCredentialNotFound) {
+		t.Errorf("got %v, want a decryption error", err)
+	}
+}
+
+func TestFileStoreMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	// Nothing has been saved yet, so no passphrase should be needed.
+	store := &fileStore{
+		Path: path,
+		Passphrase: func() (string, error) {
+			t.Error("passphrase asked for without a file")
+			return "", errors.New("no passphrase")
+		},
+	}
+	_, err := store.Get("key")
+	if !errors.Is(err, errCredentialNotFound) {
+		t.Errorf("got %v, want errCredentialNotFound", err)
+	}
+	if _, statErr := os.Stat(path); statErr == nil {
+		t.Error("file was created by a read")
+	}
+}
+
+func TestFileStoreNotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store := newTestFileStore(path, "hunter2")
+	err := store.Set("key", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = store.Get("other")
+	if !errors.Is(err, errCredentialNotFound) {
+		t.Errorf("got %v, want errCredentialNotFound", err)
+	}
+
+	// resolveToken passes it on with the account name.
+	_, err = resolveToken(store, ClientConfig{Name: "other"})
+	if err == nil || !strings.Contains(err.Error(), "account other") {
+		t.Errorf("got %v from resolveToken", err)
+	}
+}
+
+func TestFileStoreEmptyPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	err := newTestFileStore(path, "").Set("key", "token")
+	if err == nil {
+		t.Error("expected an error for an empty passphrase")
+	}
+}
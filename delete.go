@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"jaytaylorcom/html2text"
+)
+
+// Function to make sure a toot is ours before changing it.
+func ownToot(session Session, toot *mastodon.Status) bool {
+	if toot.Account.ID != session.User.ID {
+		fmt.Printf("That toot belongs to %v, you can only change your own!\n", toot.Account.Acct)
+		return false
+	}
+	return true
+}
+
+// Function to drop a deleted toot from everything shown, along with boosts of it and notifications about it.
+func forgetToot(timelines map[string]*timelineState, id string) {
+	for _, state := range timelines {
+		toots := state.Toots[:0]
+		for _, toot := range state.Toots {
+			if toot.ID != id && (toot.Reblog == nil || toot.Reblog.ID != id) {
+				toots = append(toots, toot)
+			}
+		}
+		state.Toots = toots
+
+		notes := state.Notes[:0]
+		for _, note := range state.Notes {
+			if note.Status == nil || note.Status.ID != id {
+				notes = append(notes, note)
+			}
+		}
+		state.Notes = notes
+	}
+}
+
+// Function to confirm and delete one of our toots, returning what the server deleted.
+func deleteToot(ctx context.Context, session Session, timelines map[string]*timelineState, toot *mastodon.Status) (mastodon.Status, bool) {
+	if !ownToot(session, toot) {
+		return mastodon.Status{}, false
+	}
+
+	// Show what's about to go.
+	markdown, err := html2text.FromString(toot.Content)
+	if err != nil {
+		fmt.Println(err)
+		return mastodon.Status{}, false
+	}
+	fmt.Printf("\n%v\n", markdown)
+	if !confirm("Delete this toot?") {
+		fmt.Println("Not deleted.")
+		return mastodon.Status{}, false
+	}
+
+	deleted, err := session.Client.DeleteStatus(ctx, toot.ID)
+	if err != nil {
+		printError(err)
+		return mastodon.Status{}, false
+	}
+	forgetToot(timelines, toot.ID)
+	fmt.Printf("Deleted toot %v\n\n", deleted.ID)
+	return deleted, true
+}
+
+// Function to delete one of our toots and post it again after editing.
+func redraftToot(ctx context.Context, session Session, timelines map[string]*timelineState, toot *mastodon.Status) {
+	// Keep what we need before the toot goes away.
+	original := *toot
+	deleted, ok := deleteToot(ctx, session, timelines, toot)
+	if !ok {
+		return
+	}
+
+	// The instance sends back the source text. Fall back to the HTML if it didn't.
+	text := deleted.Text
+	if text == "" {
+		markdown, err := html2text.FromString(original.Content)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		text = markdown
+	}
+	draft := mastodon.StatusParams{
+		Status:      text,
+		InReplyToID: original.InReplyToID,
+		Sensitive:   original.Sensitive,
+		SpoilerText: original.SpoilerText,
+		Visibility:  original.Visibility,
+	}
+	if len(original.MediaAttachments) > 0 {
+		fmt.Println("Attachments from the old toot aren't carried over.")
+	}
+	if original.Poll != nil {
+		poll, err := existingPoll(original.Poll, session.Limits)
+		if err != nil {
+			fmt.Printf("The poll isn't carried over: %v.\n", err)
+		} else {
+			draft.Poll = poll
+			fmt.Println("The poll is carried over, but its votes aren't.")
+		}
+	}
+
+	// Let the user change it, then post.
+	draft, err := editDraft(draft, session.Limits)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Printf("Your text was:\n%v\n", text)
+		return
+	}
+	posted, err := session.Client.PostStatus(ctx, draft)
+	if err != nil {
+		printError(err)
+		fmt.Printf("Your text was:\n%v\n", draft.Status)
+		return
+	}
+	fmt.Printf("Successfully posted toot: %v\n\n", posted.ID)
+}
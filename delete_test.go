@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/JFFail/GoToot/mastodon"
+	"testing"
+)
+
+func TestForgetToot(t *testing.T) {
+	deleted := &mastodon.Status{ID: "9", ClientID: 2}
+	home := &timelineState{
+		Name: "home",
+		Toots: []mastodon.Status{
+			{ID: "1", ClientID: 1},
+			{ID: "9", ClientID: 2},
+			{ID: "20", ClientID: 3, Reblog: deleted},
+			{ID: "21", ClientID: 4, Reblog: &mastodon.Status{ID: "5", ClientID: 4}},
+		},
+	}
+	notes := &timelineState{
+		Name: "notes",
+		Notes: []mastodon.Notification{
+			{ID: "100", Type: "favourite", Status: &mastodon.Status{ID: "9", ClientID: 5}},
+			{ID: "101", Type: "follow"},
+			{ID: "102", Type: "mention", Status: &mastodon.Status{ID: "6", ClientID: 6}},
+		},
+	}
+	timelines := map[string]*timelineState{"home": home, "notes": notes}
+
+	forgetToot(timelines, "9")
+
+	if len(home.Toots) != 2 || home.Toots[0].ID != "1" || home.Toots[1].ID != "21" {
+		t.Errorf("home kept %+v", home.Toots)
+	}
+	if len(notes.Notes) != 2 || notes.Notes[0].ID != "101" || notes.Notes[1].ID != "102" {
+		t.Errorf("notes kept %+v", notes.Notes)
+	}
+
+	// Nothing can be picked by the IDs it was shown with.
+	for _, clientID := range []int{2, 3, 5} {
+		if toot := findToot(timelines, clientID); toot != nil {
+			t.Errorf("ID %v still finds %+v", clientID, toot)
+		}
+	}
+	if toot := findToot(timelines, 4); toot == nil || toot.ID != "5" {
+		t.Errorf("ID 4 finds %+v, want the other boost", toot)
+	}
+}
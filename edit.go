@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"jaytaylorcom/html2text"
+	"strings"
+)
+
+// Function to edit one of our toots in the composer and update it in place.
+func editToot(ctx context.Context, session Session, toot *mastodon.Status) {
+	if !ownToot(session, toot) {
+		return
+	}
+
+	// Leaving the poll out of an edit would remove it, so make sure it can be sent again first.
+	var poll *mastodon.PollParams
+	if toot.Poll != nil {
+		var err error
+		poll, err = existingPoll(toot.Poll, session.Limits)
+		if err != nil {
+			fmt.Printf("Can't edit this toot: %v, and an edit would have to remove or restart it.\n\n", err)
+			return
+		}
+	}
+
+	// Start from the source text rather than the rendered HTML.
+	source, err := session.Client.GetStatusSource(ctx, toot.ID)
+	if err != nil {
+		printError(err)
+		return
+	}
+	draft, err := editDraft(mastodon.StatusParams{
+		Status:      source.Text,
+		Sensitive:   toot.Sensitive,
+		SpoilerText: source.SpoilerText,
+		Visibility:  toot.Visibility,
+	}, session.Limits)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Leaving the media out of an edit would remove it too.
+	draft.Poll = poll
+	for _, media := range toot.MediaAttachments {
+		draft.MediaIDs = append(draft.MediaIDs, media.ID)
+	}
+
+	edited, err := session.Client.EditStatus(ctx, toot.ID, draft)
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	// Keep what's on screen in line with the server.
+	toot.Content = edited.Content
+	toot.Sensitive = edited.Sensitive
+	toot.SpoilerText = edited.SpoilerText
+	toot.EditedAt = edited.EditedAt
+	toot.MediaAttachments = edited.MediaAttachments
+	toot.Poll = edited.Poll
+	fmt.Printf("Successfully edited toot: %v\n\n", edited.ID)
+}
+
+// Function to print each revision of a toot as a diff against the one before.
+func showHistory(ctx context.Context, client *mastodon.Client, toot *mastodon.Status, output outputFormat) {
+	history, err := client.StatusHistory(ctx, toot.ID)
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	// Other formats get every revision as it is.
+	if output.Name != formatText {
+		output.report(output.write(toItems(history)))
+		return
+	}
+	if len(history) <= 1 {
+		fmt.Println("That toot has never been edited.")
+		return
+	}
+
+	var previous []string
+	previousCW := ""
+	for i, revision := range history {
+		markdown, err := html2text.FromString(revision.Content)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		lines := strings.Split(markdown, "\n")
+
+		// The first revision is printed in full, the rest as changes.
+		if i == 0 {
+			fmt.Printf("> Original at %v\n", prettyTime(revision.CreatedAt))
+			if revision.SpoilerText != "" {
+				fmt.Printf(">> CW: %v\n", revision.SpoilerText)
+			}
+			fmt.Printf("\n%v\n\n", markdown)
+		} else {
+			fmt.Printf("> Edit %v at %v\n", i, prettyTime(revision.CreatedAt))
+			if revision.SpoilerText != previousCW {
+				fmt.Printf(">> CW: %q -> %q\n", previousCW, revision.SpoilerText)
+			}
+			fmt.Println()
+			for _, line := range diffLines(previous, lines) {
+				fmt.Println(line)
+			}
+			fmt.Println()
+		}
+		previous = lines
+		previousCW = revision.SpoilerText
+	}
+}
+
+// Function to diff two sets of lines, marking removals with - and additions with +.
+func diffLines(before []string, after []string) []string {
+	// Longest common subsequence lengths, working back from the end.
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	// Walk forward, keeping shared lines and marking the rest.
+	var diff []string
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			diff = append(diff, "  "+before[i])
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			diff = append(diff, "- "+before[i])
+			i++
+		default:
+			diff = append(diff, "+ "+after[j])
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		diff = append(diff, "- "+before[i])
+	}
+	for ; j < len(after); j++ {
+		diff = append(diff, "+ "+after[j])
+	}
+	return diff
+}
@@ -1,12 +1,12 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"jaytaylorcom/html2text"
+	"github.com/JFFail/GoToot/mastodon"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -14,368 +14,6 @@ import (
 	"time"
 )
 
-// Struct for the .json configuration.
-type ClientConfig struct {
-	Token    string `json:"access_token"`
-	Instance string `json:"instance"`
-}
-
-// Struct for the user's account.
-type CurrentUser struct {
-	ID             string    `json:"id"`
-	Username       string    `json:"username"`
-	Acct           string    `json:"acct"`
-	DisplayName    string    `json:"display_name"`
-	Locked         bool      `json:"locked"`
-	Bot            bool      `json:"bot"`
-	Discoverable   bool      `json:"discoverable"`
-	Group          bool      `json:"group"`
-	CreatedAt      time.Time `json:"created_at"`
-	Note           string    `json:"note"`
-	URL            string    `json:"url"`
-	Avatar         string    `json:"avatar"`
-	AvatarStatic   string    `json:"avatar_static"`
-	Header         string    `json:"header"`
-	HeaderStatic   string    `json:"header_static"`
-	FollowersCount int       `json:"followers_count"`
-	FollowingCount int       `json:"following_count"`
-	StatusesCount  int       `json:"statuses_count"`
-	LastStatusAt   string    `json:"last_status_at"`
-	Source         struct {
-		Privacy   string      `json:"privacy"`
-		Sensitive bool        `json:"sensitive"`
-		Language  interface{} `json:"language"`
-		Note      string      `json:"note"`
-		Fields    []struct {
-			Name       string    `json:"name"`
-			Value      string    `json:"value"`
-			VerifiedAt time.Time `json:"verified_at"`
-		} `json:"fields"`
-		FollowRequestsCount int `json:"follow_requests_count"`
-	} `json:"source"`
-	Emojis []interface{} `json:"emojis"`
-	Fields []struct {
-		Name       string    `json:"name"`
-		Value      string    `json:"value"`
-		VerifiedAt time.Time `json:"verified_at"`
-	} `json:"fields"`
-}
-
-// Struct for a single toot. Used in response when posting.
-type SingleToot struct {
-	ID                 string `json:"id"`
-	ClientID           int
-	CreatedAt          time.Time   `json:"created_at"`
-	InReplyToID        interface{} `json:"in_reply_to_id"`
-	InReplyToAccountID interface{} `json:"in_reply_to_account_id"`
-	Sensitive          bool        `json:"sensitive"`
-	SpoilerText        string      `json:"spoiler_text"`
-	Visibility         string      `json:"visibility"`
-	Language           string      `json:"language"`
-	URI                string      `json:"uri"`
-	URL                string      `json:"url"`
-	RepliesCount       int         `json:"replies_count"`
-	ReblogsCount       int         `json:"reblogs_count"`
-	FavouritesCount    int         `json:"favourites_count"`
-	Favourited         bool        `json:"favourited"`
-	Reblogged          bool        `json:"reblogged"`
-	Muted              bool        `json:"muted"`
-	Bookmarked         bool        `json:"bookmarked"`
-	Pinned             bool        `json:"pinned"`
-	Content            string      `json:"content"`
-	Reblog             interface{} `json:"reblog"`
-	Application        struct {
-		Name    string `json:"name"`
-		Website string `json:"website"`
-	} `json:"application"`
-	Account struct {
-		ID             string        `json:"id"`
-		Username       string        `json:"username"`
-		Acct           string        `json:"acct"`
-		DisplayName    string        `json:"display_name"`
-		Locked         bool          `json:"locked"`
-		Bot            bool          `json:"bot"`
-		Discoverable   bool          `json:"discoverable"`
-		Group          bool          `json:"group"`
-		CreatedAt      time.Time     `json:"created_at"`
-		Note           string        `json:"note"`
-		URL            string        `json:"url"`
-		Avatar         string        `json:"avatar"`
-		AvatarStatic   string        `json:"avatar_static"`
-		Header         string        `json:"header"`
-		HeaderStatic   string        `json:"header_static"`
-		FollowersCount int           `json:"followers_count"`
-		FollowingCount int           `json:"following_count"`
-		StatusesCount  int           `json:"statuses_count"`
-		LastStatusAt   string        `json:"last_status_at"`
-		Emojis         []interface{} `json:"emojis"`
-		Fields         []struct {
-			Name       string    `json:"name"`
-			Value      string    `json:"value"`
-			VerifiedAt time.Time `json:"verified_at"`
-		} `json:"fields"`
-	} `json:"account"`
-	MediaAttachments []interface{} `json:"media_attachments"`
-	Mentions         []interface{} `json:"mentions"`
-	Tags             []interface{} `json:"tags"`
-	Emojis           []interface{} `json:"emojis"`
-	Card             interface{}   `json:"card"`
-	Poll             interface{}   `json:"poll"`
-}
-
-// Struct for notifications.
-type Notification struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	CreatedAt time.Time `json:"created_at"`
-	Account   struct {
-		ID             string        `json:"id"`
-		Username       string        `json:"username"`
-		Acct           string        `json:"acct"`
-		DisplayName    string        `json:"display_name"`
-		Locked         bool          `json:"locked"`
-		Bot            bool          `json:"bot"`
-		Discoverable   bool          `json:"discoverable"`
-		Group          bool          `json:"group"`
-		CreatedAt      time.Time     `json:"created_at"`
-		Note           string        `json:"note"`
-		URL            string        `json:"url"`
-		Avatar         string        `json:"avatar"`
-		AvatarStatic   string        `json:"avatar_static"`
-		Header         string        `json:"header"`
-		HeaderStatic   string        `json:"header_static"`
-		FollowersCount int           `json:"followers_count"`
-		FollowingCount int           `json:"following_count"`
-		StatusesCount  int           `json:"statuses_count"`
-		LastStatusAt   string        `json:"last_status_at"`
-		Emojis         []interface{} `json:"emojis"`
-		Fields         []struct {
-			Name       string      `json:"name"`
-			Value      string      `json:"value"`
-			VerifiedAt interface{} `json:"verified_at"`
-		} `json:"fields"`
-	} `json:"account"`
-	Status struct {
-		ID                 string `json:"id"`
-		ClientID           int
-		CreatedAt          time.Time   `json:"created_at"`
-		InReplyToID        string      `json:"in_reply_to_id"`
-		InReplyToAccountID string      `json:"in_reply_to_account_id"`
-		Sensitive          bool        `json:"sensitive"`
-		SpoilerText        string      `json:"spoiler_text"`
-		Visibility         string      `json:"visibility"`
-		Language           string      `json:"language"`
-		URI                string      `json:"uri"`
-		URL                string      `json:"url"`
-		RepliesCount       int         `json:"replies_count"`
-		ReblogsCount       int         `json:"reblogs_count"`
-		FavouritesCount    int         `json:"favourites_count"`
-		Favourited         bool        `json:"favourited"`
-		Reblogged          bool        `json:"reblogged"`
-		Muted              bool        `json:"muted"`
-		Bookmarked         bool        `json:"bookmarked"`
-		Content            string      `json:"content"`
-		Reblog             interface{} `json:"reblog"`
-		Application        struct {
-			Name    string `json:"name"`
-			Website string `json:"website"`
-		} `json:"application"`
-		Account struct {
-			ID             string        `json:"id"`
-			Username       string        `json:"username"`
-			Acct           string        `json:"acct"`
-			DisplayName    string        `json:"display_name"`
-			Locked         bool          `json:"locked"`
-			Bot            bool          `json:"bot"`
-			Discoverable   bool          `json:"discoverable"`
-			Group          bool          `json:"group"`
-			CreatedAt      time.Time     `json:"created_at"`
-			Note           string        `json:"note"`
-			URL            string        `json:"url"`
-			Avatar         string        `json:"avatar"`
-			AvatarStatic   string        `json:"avatar_static"`
-			Header         string        `json:"header"`
-			HeaderStatic   string        `json:"header_static"`
-			FollowersCount int           `json:"followers_count"`
-			FollowingCount int           `json:"following_count"`
-			StatusesCount  int           `json:"statuses_count"`
-			LastStatusAt   string        `json:"last_status_at"`
-			Emojis         []interface{} `json:"emojis"`
-			Fields         []struct {
-				Name       string      `json:"name"`
-				Value      string      `json:"value"`
-				VerifiedAt interface{} `json:"verified_at"`
-			} `json:"fields"`
-		} `json:"account"`
-		MediaAttachments []interface{} `json:"media_attachments"`
-		Mentions         []struct {
-			ID       string `json:"id"`
-			Username string `json:"username"`
-			URL      string `json:"url"`
-			Acct     string `json:"acct"`
-		} `json:"mentions"`
-		Tags   []interface{} `json:"tags"`
-		Emojis []interface{} `json:"emojis"`
-		Card   interface{}   `json:"card"`
-		Poll   interface{}   `json:"poll"`
-	} `json:"status"`
-}
-
-// Function to query data from Mastodon.
-func queryMasto(bearer string, url string) []byte {
-	// Create an HTTP client
-	client := &http.Client{}
-	request, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(3)
-	}
-
-	// Set the header.
-	request.Header.Set("Authorization", bearer)
-
-	// Make the request.
-	response, err := client.Do(request)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(4)
-	}
-
-	defer response.Body.Close()
-
-	// Read the data.
-	respData, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(5)
-	}
-
-	// Return the data.
-	return respData
-}
-
-// Function to push content to Mastodon.
-func postToMasto(bearer string, url string, content string, replyId string, sensitive bool, spoiler string) string {
-	// Create the url.
-	url = fmt.Sprintf("%v/statuses", url)
-
-	// Create the map for the form data.
-	formData := make(map[string]string)
-	formData["status"] = content
-	if replyId != "" {
-		formData["in_reply_to_id"] = replyId
-	}
-	if sensitive {
-		formData["sensitive"] = "true"
-		formData["spoiler_text"] = spoiler
-	}
-
-	// Put together the form body.
-	reqBody, err := json.Marshal(formData)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(10)
-	}
-
-	// Put together the client.
-	client := &http.Client{}
-	request, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(11)
-	}
-	request.Header.Set("Authorization", bearer)
-	request.Header.Set("Content-Type", "application/json")
-
-	// Make the request.
-	response, err := client.Do(request)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(12)
-	}
-
-	defer response.Body.Close()
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(13)
-	}
-
-	// Parse the toot to a struct and return the ID.
-	var postedToot SingleToot
-	err = json.Unmarshal(body, &postedToot)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(14)
-	}
-
-	return postedToot.ID
-}
-
-func verifyToken(bearer string, url string) bool {
-	// Append the endpoint to the URL.
-	fullUrl := fmt.Sprintf("%v/apps/verify_credentials", url)
-
-	// Query.
-	queryResult := queryMasto(bearer, fullUrl)
-
-	// Ensure it's not null.
-	if queryResult != nil {
-		return true
-	} else {
-		return false
-	}
-}
-
-// Function to verify the user.
-func verifyUserCreds(bearer string, url string) CurrentUser {
-	// Append the endpoint to the URL.
-	fullUrl := fmt.Sprintf("%v/accounts/verify_credentials", url)
-
-	// Query.
-	queryResult := queryMasto(bearer, fullUrl)
-
-	// Create the user.
-	var thisUser CurrentUser
-	err := json.Unmarshal(queryResult, &thisUser)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(7)
-	}
-
-	return thisUser
-}
-
-// Function to get toot content.
-func getTootContent() string {
-	var text string
-	var err error
-	reader := bufio.NewReader(os.Stdin)
-	shortEnough := false
-	// Prompt the user for their text.
-	fmt.Printf("\nEnter your toot.\n")
-	for !shortEnough {
-		fmt.Print("> ")
-		text, err = reader.ReadString('\n')
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(9)
-		}
-
-		// Trim the return.
-		text = strings.Trim(text, "\n")
-
-		// Verify we're within the length limit.
-		if len(text) > 500 {
-			fmt.Println("That toot is too long! Try again...")
-			continue
-		} else {
-			shortEnough = true
-		}
-	}
-	return text
-}
-
 // Function to get the ID of a toot to boost or favorite.
 func getTootID() int {
 	// Prompt the user.
@@ -383,8 +21,7 @@ func getTootID() int {
 	fmt.Print("> ")
 
 	// Get user input.
-	reader := bufio.NewReader(os.Stdin)
-	userInput, err := reader.ReadString('\n')
+	userInput, err := stdin.ReadString('\n')
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(22)
@@ -403,115 +40,88 @@ func getTootID() int {
 }
 
 // Function to print the toots in a timeline.
-func printToots(allToots []SingleToot) {
+func printToots(allToots []mastodon.Status) {
 	// Loop through the slice backwards.
 	for i := len(allToots) - 1; i >= 0; i-- {
-		// Parse the HTML of the post to Markdown-like text.
-		markdown, err := html2text.FromString(allToots[i].Content)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(15)
+		// Collapse boosts of the same toot that come one after another.
+		toot := allToots[i]
+		var boostedBy []mastodon.Account
+		if toot.Reblog != nil {
+			boostedBy = append(boostedBy, toot.Account)
+			for i > 0 && allToots[i-1].Reblog != nil && allToots[i-1].Reblog.ID == toot.Reblog.ID {
+				i--
+				boostedBy = append(boostedBy, allToots[i].Account)
+			}
 		}
+		printToot(toot, boostedBy, "")
+	}
+}
 
-		// Modify the date.
-		datePretty := strings.Split(allToots[i].CreatedAt.String(), ".")
-
-		// Print the author, app, and timestamp.
-		applicationName := "Web"
-		if allToots[i].Application.Name != "" {
-			applicationName = allToots[i].Application.Name
-		}
-		fmt.Printf("> %v from |%v| to |%v| at %v\n", allToots[i].Account.Acct, applicationName, allToots[i].Visibility, datePretty[0])
+// Function to show a time in the user's own timezone.
+func prettyTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05 MST")
+}
 
-		// Check if there's a CW.
-		if allToots[i].Sensitive {
-			// Print it.
-			fmt.Printf(">> CW: %v\n", allToots[i].SpoilerText)
-		}
+// Function to print a single toot with every line indented.
+func printToot(toot mastodon.Status, boostedBy []mastodon.Account, indent string) {
+	// Render it with the template for its kind, leaving room for the indent.
+	width := terminalWidth
+	if width > 0 {
+		width = max(width-countGraphemes(indent), minWrapWidth)
+	}
+	item := renderItem{Kind: tootKind(toot), Toot: &toot, Width: width}
 
-		// Print the main toot content parsed to Markdown.
-		if allToots[i].Reblogged {
-			fmt.Printf("\n%v\n\n", markdown)
-		} else {
-			fmt.Printf("\n%v\n", markdown)
+	// Boosts show the boosted toot along with who boosted it.
+	if toot.Reblog != nil {
+		if len(boostedBy) == 0 {
+			boostedBy = []mastodon.Account{toot.Account}
 		}
+		item.Toot = toot.Reblog
+		item.BoostedBy = boostedBy
+	}
+	output, err := render(item)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-		// Check if there's media.
-		media := allToots[i].MediaAttachments
-		if len(media) > 0 {
-			// Loop through it.
-			for i := len(media) - 1; i >= 0; i-- {
-				// Get the type of media and the URL to it.
-				mediaType := media[i].(map[string]interface{})["type"]
-				mediaURL := media[i].(map[string]interface{})["text_url"]
-				fmt.Printf("%v: %v\n", mediaType, mediaURL)
-			}
+	// Indent every line that has something on it.
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
 		}
-		fmt.Printf("~=: ID: %v\tFavs: %v\tBoosts: %v :=~\n", allToots[i].ClientID, allToots[i].FavouritesCount, allToots[i].ReblogsCount)
-		fmt.Printf("\n")
 	}
+	fmt.Print(strings.Join(lines, "\n"))
+	fmt.Printf("\n")
 }
 
 // Function to print notifications.
-func printNotifications(allNotifications []Notification) {
+func printNotifications(allNotifications []mastodon.Notification) {
 	// Loop through the slice backwards.
 	for i := len(allNotifications) - 1; i >= 0; i-- {
-		// Check the type.
-		if allNotifications[i].Type == "mention" {
-			// Modify the date.
-			datePretty := strings.Split(allNotifications[i].Status.CreatedAt.String(), ".")
-
-			// Get the application used.
-			applicationName := "Web"
-			if allNotifications[i].Status.Application.Name != "" {
-				applicationName = allNotifications[i].Status.Application.Name
-			}
-			fmt.Printf("> Mention by %v from |%v| to |%v| at %v\n", allNotifications[i].Account.Acct, applicationName, allNotifications[i].Status.Visibility, datePretty[0])
-		} else if allNotifications[i].Type == "favourite" || allNotifications[i].Type == "boost" {
-			// Print the info on the fav/boost and for what toot.
-			if allNotifications[i].Type == "favourite" {
-				fmt.Printf("> Favorite by %v\n", allNotifications[i].Account.Acct)
-			} else {
-				fmt.Printf("> Boost by %v\n", allNotifications[i].Account.Acct)
-			}
-		} else if allNotifications[i].Type == "follow" {
-			// Print information about who followed.
-			markdown, err := html2text.FromString(allNotifications[i].Account.Note)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(23)
-			}
-			fmt.Printf("> Followed by %v\n", allNotifications[i].Account.Acct)
-			fmt.Printf(">> Has posted %v statuses, the last on %v\n", allNotifications[i].Account.StatusesCount, allNotifications[i].Account.LastStatusAt)
-			fmt.Printf("%v\n", markdown)
-			fmt.Printf("~=: Following: %v\tFollowers: %v :=~\n\n", allNotifications[i].Account.FollowingCount, allNotifications[i].Account.FollowersCount)
-		} else {
-			// Probably change this later, yeah?
-			fmt.Printf("%+v\n", allNotifications[i])
-			fmt.Println("Not sure what to do with a type of %v\n", allNotifications[i].Type)
-		}
-
-		// Parse the toot content and print it if there is any.
-		if allNotifications[i].Status.Content != "" {
-			markdown, err := html2text.FromString(allNotifications[i].Status.Content)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(18)
-			}
-			fmt.Printf("\n%v\n", markdown)
-			fmt.Printf("~=: ID: %v\tFavs: %v\tBoosts: %v :=~\n\n", allNotifications[i].Status.ClientID, allNotifications[i].Status.FavouritesCount, allNotifications[i].Status.ReblogsCount)
+		// Render each one with the template for its type.
+		note := &allNotifications[i]
+		output, err := render(renderItem{Kind: notificationKind(*note), Toot: note.Status, Notification: note, Width: terminalWidth})
+		if err != nil {
+			fmt.Println(err)
+			continue
 		}
+		fmt.Print(output)
 	}
 }
 
 // Function to assign indexes to all toots for reference.
-func assignIndexToots(allToots []SingleToot, indexStart int) ([]SingleToot, int) {
+func assignIndexToots(allToots []mastodon.Status, indexStart int) ([]mastodon.Status, int) {
 	for i := len(allToots) - 1; i >= 0; i-- {
 		// Increment the counter.
 		indexStart++
 
-		// Assign the ID.
+		// Assign the ID, to the boosted toot as well so actions can find it.
 		allToots[i].ClientID = indexStart
+		if allToots[i].Reblog != nil {
+			allToots[i].Reblog.ClientID = indexStart
+		}
 	}
 
 	// Return the updated array and the new index.
@@ -519,207 +129,358 @@ func assignIndexToots(allToots []SingleToot, indexStart int) ([]SingleToot, int)
 }
 
 // Function to assign an index to notifications.
-func assignIndexNotes(allNotes []Notification, indexStart int) ([]Notification, int) {
+func assignIndexNotes(allNotes []mastodon.Notification, indexStart int) ([]mastodon.Notification, int) {
 	for i := len(allNotes) - 1; i >= 0; i-- {
 		// Increment the counter.
 		indexStart++
 
 		// Assign the ID.
-		allNotes[i].Status.ClientID = indexStart
+		if allNotes[i].Status != nil {
+			allNotes[i].Status.ClientID = indexStart
+		}
 	}
 
 	// Return the updated slice and the new index.
 	return allNotes, indexStart
 }
 
-// Function to favorite a toot.
-func favOrBoostToot(bearer string, url string, tootID string, tootBody string, updateType string) {
-	// Parse the appropriate URL.
-	if updateType == "boost" {
-		url = fmt.Sprintf("%v/statuses/%v/reblog", url, tootID)
-	} else {
-		url = fmt.Sprintf("%v/statuses/%v/favourite", url, tootID)
-	}
+// Function to print an error from the API in a readable way.
+func printError(err error) {
+	writeError(os.Stdout, err)
+}
 
-	// Put together the client.
-	client := &http.Client{}
-	request, err := http.NewRequest("POST", url, nil)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(19)
+// Function to write an error with a hint about what went wrong.
+func writeError(w io.Writer, err error) {
+	var apiErr *mastodon.APIError
+	if !errors.As(err, &apiErr) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
 	}
-	request.Header.Set("Authorization", bearer)
-	request.Header.Set("Content-Type", "application/json")
 
-	// Make the request.
-	_, err = client.Do(request)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(20)
-	} else {
-		// Parse the toot content to plaintext.
-		markdown, err := html2text.FromString(tootBody)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(21)
-		}
-		if updateType == "boost" {
-			fmt.Printf("Successfully boosted: %v\n", markdown)
-		} else {
-			fmt.Printf("Successfully favorited: %v\n", markdown)
-		}
+	// Explain the common failures.
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		fmt.Fprintf(w, "Not allowed: %v\n", apiErr.Error())
+		fmt.Fprintln(w, "The token may have been revoked. Run \"GoToot login\" to get a new one.")
+	case http.StatusNotFound:
+		fmt.Fprintf(w, "Not found: %v\n", apiErr.Error())
+	case http.StatusUnprocessableEntity:
+		fmt.Fprintf(w, "Rejected by the instance: %v\n", apiErr.Error())
+	default:
+		fmt.Fprintf(w, "Error from the instance: %v\n", apiErr.Error())
 	}
 }
 
 // Main function.
 func main() {
-	// Import the file with the config.
-	config, err := ioutil.ReadFile("./client.json")
+	// Parse the command line flags.
+	accountFlag := flag.String("account", "", "name of the account to use from the config")
+	configFlag := flag.String("config", "", "path to the config file")
+	storeFlag := flag.String("store", "", "where login saves the token: plaintext, keyring or file")
+	formatFlag := flag.String("format", "", "output format for read commands: text, json, ndjson or template")
+	templateFlag := flag.String("template", "", "Go template for each item with the template format")
+	flag.Parse()
+
+	// Find the config file.
+	configPath, configFound := findConfig(*configFlag)
+
+	// Log in and write the config if asked to.
+	if flag.Arg(0) == "login" {
+		login(configPath, *accountFlag, *storeFlag)
+		return
+	}
+
+	// Anything else is a single command, which must never wait for input.
+	if flag.NArg() > 0 {
+		noPrompt = true
+	}
+
+	// Work out how to write what read commands fetch.
+	output, err := chooseOutputFormat(*formatFlag, *templateFlag, outputFormat{Name: formatText})
 	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
-	// Create the struct with the config.
-	var configInfo ClientConfig
-	err = json.Unmarshal(config, &configInfo)
+	// Load the config and pick the account. The environment alone is enough if there's no file.
+	var configInfo Config
+	if configFound || os.Getenv("GOTOOT_TOKEN") == "" {
+		configInfo = loadConfig(configPath)
+	}
+	account, found := configInfo.account(*accountFlag)
+	if !found && (configFound || *accountFlag != "") {
+		// Say which name was looked up, which is the default if none was given.
+		accountName := *accountFlag
+		if accountName == "" {
+			accountName = configInfo.DefaultAccount
+		}
+		if accountName == "" {
+			fmt.Println("There are no accounts in the config! Run \"GoToot login\" to add one.")
+		} else {
+			fmt.Printf("No account named %v in the config!\n", accountName)
+		}
+		os.Exit(34)
+	}
+
+	// Open the credential store holding the tokens.
+	store, err := openCredentialStore(configInfo, configPath)
 	if err != nil {
 		fmt.Println(err)
-		os.Exit(2)
+		os.Exit(38)
 	}
+	// The environment only overrides the account picked here, not later switches.
+	account = prepareAccount(applyEnvOverrides(account), store)
 
 	// Initialize the counter for toot IDs.
 	tootCounter := 0
 
-	// Create the base URL for our instance.
-	baseURL := fmt.Sprintf("%v/api/v1", configInfo.Instance)
+	// Work out how many toots to fetch at a time.
+	pageSize := configInfo.PageSize
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
 
-	// Create the header we'll use for authorization.
-	bearerHeader := fmt.Sprintf("Bearer %v", configInfo.Token)
+	// Use the config's templates in place of the built-in ones.
+	renderTemplates, err = loadTemplates(configInfo.Templates)
+	if err != nil {
+		fmt.Printf("Bad template in the config: %v\n", err)
+		os.Exit(45)
+	}
 
-	// Verify the token is valid.
-	if !verifyToken(bearerHeader, baseURL) {
-		fmt.Println("Token is invalid!")
-		os.Exit(6)
+	// Pick the theme, and colour and wrap the output if it's going to a terminal.
+	err = setupTerminal(configInfo)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(46)
 	}
 
-	// Verify the user information.
-	currentUser := verifyUserCreds(bearerHeader, baseURL)
-	fmt.Printf("Logged in as: %v\n", currentUser.Acct)
-	fmt.Printf("%v statuses, last one posted on %v\n\n", currentUser.StatusesCount, currentUser.LastStatusAt)
+	// Run a single command and exit if one was given.
+	ctx := context.Background()
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(ctx, account, flag.Args(), pageSize, output))
+	}
+
+	// Verify the account and set up the session.
+	session, err := connect(ctx, account)
+	if err != nil {
+		printError(err)
+		os.Exit(6)
+	}
+	session.greet()
 
 	// Start the main loop to see what the user would like to do.
 	var userChoice string
-	var cwText string
-	var currentPost string
-	var currentTimeline []byte
-	var currentNotifications []byte
-	var currentTLParsed []SingleToot
-	var currentNotesParsed []Notification
-	var lastTootsReceived string
-	userPrompt := fmt.Sprintf("[%v]: ", currentUser.Acct)
-	reader := bufio.NewReader(os.Stdin)
+	var shown *timelineState
+	var scheduled []mastodon.ScheduledStatus
+	timelines := make(map[string]*timelineState)
 	for userChoice != "quit" {
-		fmt.Print(userPrompt)
+		fmt.Print(session.prompt())
 
 		// Get the user's input.
-		text, err := reader.ReadString('\n')
+		text, err := stdin.ReadString('\n')
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(8)
 		}
-		userChoice = strings.ToLower(strings.Trim(text, "\n"))
+
+		// Split the command from its arguments.
+		userArgs := strings.Fields(text)
+		if len(userArgs) == 0 {
+			continue
+		}
+		userChoice = strings.ToLower(userArgs[0])
+		userArgs = userArgs[1:]
 
 		// Figure out what action to take based on user input.
 		switch userChoice {
-		case "home":
-			// Get the byte slice for the timeline.
-			currentTimeline = queryMasto(bearerHeader, fmt.Sprintf("%v/timelines/home?limit=2", baseURL))
-			err = json.Unmarshal(currentTimeline, &currentTLParsed)
+		case "home", "local", "note", "notes":
+			// Figure out which timeline and how much of it.
+			name := timelineName(userChoice)
+			limit := pageSize
+			if len(userArgs) > 0 {
+				limit, err = strconv.Atoi(userArgs[0])
+				if err != nil || limit < 1 {
+					fmt.Printf("%v is not a valid page size!\n", userArgs[0])
+					continue
+				}
+			}
+
+			// Start the timeline over from the newest toots.
+			state := &timelineState{Name: name, Limit: limit}
+			tootCounter, err = showPage(ctx, session.Client, state, pageFirst, tootCounter, output)
+			if err != nil {
+				printError(err)
+				continue
+			}
+			timelines[name] = state
+			shown = state
+		case "more", "newer":
+			// Page through the named timeline, or whatever was shown last.
+			state := shown
+			if len(userArgs) > 0 {
+				state = timelines[timelineName(userArgs[0])]
+			}
+			if state == nil {
+				fmt.Println("Show a timeline first with home, local or notes.")
+				continue
+			}
+			direction := pageOlder
+			if userChoice == "newer" {
+				direction = pageNewer
+			}
+			tootCounter, err = showPage(ctx, session.Client, state, direction, tootCounter, output)
+			if err != nil {
+				printError(err)
+				continue
+			}
+			shown = state
+		case "stream":
+			// Work out which stream to follow.
+			params, err := parseStreamArgs(userArgs)
 			if err != nil {
 				fmt.Println(err)
-				os.Exit(15)
+				continue
 			}
 
-			// Assign each toot an index for this app.
-			currentTLParsed, tootCounter = assignIndexToots(currentTLParsed, tootCounter)
-
-			//fmt.Printf("%+v\n", currentTLParsed)
-			printToots(currentTLParsed)
-
-			// Set where we got toots from.
-			lastTootsReceived = "tl"
-		case "local":
-			// Get the byte slice.
-			currentTimeline = queryMasto(bearerHeader, fmt.Sprintf("%v/timelines/public?local=true&limit=2", baseURL))
-			err = json.Unmarshal(currentTimeline, &currentTLParsed)
+			// Follow it until the user stops it.
+			state, counter, err := runStream(ctx, session.Client, params, tootCounter, output)
+			tootCounter = counter
+			timelines[state.Name] = state
+			shown = state
+			if err != nil {
+				printError(err)
+			}
+		case "toot", "cwtoot":
+			// Work out the visibility and so on.
+			options, _, err := parseComposeOptions(userArgs)
 			if err != nil {
 				fmt.Println(err)
-				os.Exit(16)
+				continue
+			}
+			composeToot(ctx, session, options, userChoice == "cwtoot")
+		case "fav", "unfav", "boost", "unboost", "bookmark", "unbookmark":
+			// Find the toot to act on.
+			toot := selectToot(timelines, userArgs)
+			if toot == nil {
+				continue
 			}
-			currentTLParsed, tootCounter = assignIndexToots(currentTLParsed, tootCounter)
-			printToots(currentTLParsed)
-			lastTootsReceived = "tl"
-		case "note", "notes":
-			// Get the byte slice.
-			currentNotifications = queryMasto(bearerHeader, fmt.Sprintf("%v/notifications?limit=2", baseURL))
-
-			// Parse to a struct.
-			err = json.Unmarshal(currentNotifications, &currentNotesParsed)
+			runTootAction(ctx, session.Client, tootActions[userChoice], toot)
+		case "reply":
+			// Find the toot being replied to.
+			options, userArgs, err := parseComposeOptions(userArgs)
 			if err != nil {
 				fmt.Println(err)
-				os.Exit(17)
+				continue
+			}
+			toot := selectToot(timelines, userArgs)
+			if toot == nil {
+				continue
 			}
-			currentNotesParsed, tootCounter = assignIndexNotes(currentNotesParsed, tootCounter)
-
-			// Print the notifications.
-			printNotifications(currentNotesParsed)
-			lastTootsReceived = "notes"
-		case "toot":
-			// Prompt the user for their text.
-			text := getTootContent()
-
-			// Pass to the function.
-			currentPost = postToMasto(bearerHeader, baseURL, text, "", false, "")
-			fmt.Printf("Successfully posted toot: %v\n\n", currentPost)
-		case "cwtoot":
-			// Prompt the user for their spoiler text.
-			fmt.Printf("\nEnter your spoiler text.\n")
-			fmt.Print("> ")
-			cwText, err = reader.ReadString('\n')
-
-			// Prompt the user for their text.
-			text := getTootContent()
-			// Pass to the function.
-			currentPost = postToMasto(bearerHeader, baseURL, text, "", true, cwText)
-			fmt.Printf("Successfully posted toot: %v\n\n", currentPost)
-		case "fav":
-			// Prompt the user for the ID of the toot to fav.
-			tootSelection := getTootID()
-
-			// Don't do anything if it was 0.
-			if tootSelection != 0 {
-				if lastTootsReceived == "" {
-					fmt.Println("No toots in the local database to fav!")
-				} else if lastTootsReceived == "tl" {
-					for _, toot := range currentTLParsed {
-						if toot.ClientID == tootSelection {
-							// Submit the fav and break.
-							favOrBoostToot(bearerHeader, baseURL, toot.ID, toot.Content, "fav")
-							break
-						}
-					}
-				} else {
-					for _, note := range currentNotesParsed {
-						if note.Type == "mention" {
-							if note.Status.ClientID == tootSelection {
-								favOrBoostToot(bearerHeader, baseURL, note.Status.ID, note.Status.Content, "fav")
-								break
-							}
-						}
-					}
+			replyToToot(ctx, session, toot, options)
+		case "thread":
+			// Find the toot to show the thread for.
+			toot := selectToot(timelines, userArgs)
+			if toot == nil {
+				continue
+			}
+
+			// Show it and make its toots available to act on.
+			state, counter, err := showThread(ctx, session.Client, *toot, tootCounter, output)
+			if err != nil {
+				printError(err)
+				continue
+			}
+			tootCounter = counter
+			timelines[state.Name] = state
+			shown = state
+		case "delete", "redraft":
+			// Find the toot to delete.
+			toot := selectToot(timelines, userArgs)
+			if toot == nil {
+				continue
+			}
+			if userChoice == "delete" {
+				deleteToot(ctx, session, timelines, toot)
+			} else {
+				redraftToot(ctx, session, timelines, toot)
+			}
+		case "edit", "history":
+			// Find the toot.
+			toot := selectToot(timelines, userArgs)
+			if toot == nil {
+				continue
+			}
+			if userChoice == "edit" {
+				editToot(ctx, session, toot)
+			} else {
+				showHistory(ctx, session.Client, toot, output)
+			}
+		case "vote":
+			// Find the toot with the poll.
+			toot := selectToot(timelines, userArgs)
+			if toot == nil {
+				continue
+			}
+			var choices []string
+			if len(userArgs) > 1 {
+				choices = userArgs[1:]
+			}
+			voteInPoll(ctx, session.Client, toot, choices)
+		case "format":
+			// Show or change how read commands write what they fetch.
+			if len(userArgs) == 0 {
+				fmt.Printf("Output format is %v.\n", output.Name)
+				continue
+			}
+			newOutput, err := parseOutputFormat(strings.ToLower(userArgs[0]), strings.Join(userArgs[1:], " "))
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			output = newOutput
+			fmt.Printf("Output format is now %v.\n", output.Name)
+		case "scheduled":
+			// List, reschedule or cancel toots waiting to be posted.
+			scheduled, err = runScheduled(ctx, session.Client, userArgs, scheduled, output)
+			if err != nil {
+				printError(err)
+			}
+		case "ratelimit":
+			// Show what's left of the quota.
+			limit := session.Client.RateLimit()
+			if !limit.Known {
+				fmt.Println("No rate limit information yet.")
+				continue
+			}
+			resetIn := time.Until(limit.Reset).Round(time.Second)
+			fmt.Printf("%v of %v requests remaining, resets at %v (in %v)\n", limit.Remaining, limit.Limit, limit.Reset.Local().Format("15:04:05"), resetIn)
+		case "account":
+			// List the accounts if no name was given.
+			if len(userArgs) == 0 {
+				for _, configured := range configInfo.Accounts {
+					fmt.Printf("%v\t%v\n", configured.Name, configured.Instance)
 				}
+				continue
 			}
+
+			// Find the account and switch to it.
+			newAccount, found := configInfo.account(userArgs[0])
+			if !found {
+				fmt.Printf("No account named %v in the config!\n", userArgs[0])
+				continue
+			}
+			newSession, err := connect(ctx, prepareAccount(newAccount, store))
+			if err != nil {
+				printError(err)
+				continue
+			}
+			session = newSession
+			session.greet()
+
+			// Toots from the old account can't be acted on anymore.
+			timelines = make(map[string]*timelineState)
+			shown = nil
+			scheduled = nil
 		case "exit":
 			// Just reset the userChoice variable to quit.
 			userChoice = "quit"
@@ -0,0 +1,204 @@
+// Package mastodon is a small client for the Mastodon REST API.
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Struct for a connection to one account on one instance.
+type Client struct {
+	// Instance URL without a trailing slash, e.g. https://mastodon.social.
+	Instance string
+	// Access token, empty before logging in.
+	Token string
+	// HTTP client shared by every request.
+	HTTPClient *http.Client
+	// How many times a failed request is retried.
+	MaxRetries int
+
+	// Rate limit from the last response.
+	mu        sync.Mutex
+	rateLimit RateLimit
+}
+
+// Struct for a response that has been read in full.
+type rawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Function to create a client for an instance and token.
+func NewClient(instance string, token string) *Client {
+	return &Client{
+		Instance:   instance,
+		Token:      token,
+		HTTPClient: &http.Client{},
+		MaxRetries: 3,
+	}
+}
+
+// Function to send a request and decode the JSON response into out, returning the response headers.
+func (c *Client) do(ctx context.Context, method string, path string, params url.Values, body interface{}, out interface{}) (http.Header, error) {
+	// Put together the URL.
+	fullURL := c.Instance + path
+	if len(params) > 0 {
+		fullURL = fmt.Sprintf("%v?%v", fullURL, params.Encode())
+	}
+
+	// Put together the body.
+	var encoded []byte
+	contentType := ""
+	if body != nil {
+		var err error
+		encoded, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		contentType = "application/json"
+	}
+
+	response, err := c.roundTrip(ctx, method, path, fullURL, encoded, contentType, out)
+	return response.Header, err
+}
+
+// Function to make a request with an already encoded body and decode the JSON response into out.
+func (c *Client) roundTrip(ctx context.Context, method string, path string, fullURL string, body []byte, contentType string, out interface{}) (rawResponse, error) {
+	// Make the request.
+	response, err := c.send(ctx, method, fullURL, body, contentType)
+	if err != nil {
+		return response, err
+	}
+
+	// Anything outside of 2xx means the request didn't happen.
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response, newAPIError(method, path, response.StatusCode, response.Body)
+	}
+
+	// Parse the response if the caller wants it.
+	if out == nil {
+		return response, nil
+	}
+	err = json.Unmarshal(response.Body, out)
+	if err != nil {
+		return response, fmt.Errorf("%v %v: could not parse response: %v", method, path, err)
+	}
+	return response, nil
+}
+
+// Function to make a request, waiting out rate limits and retrying failed GETs.
+func (c *Client) send(ctx context.Context, method string, fullURL string, body []byte, contentType string) (rawResponse, error) {
+	// Only GETs are safe to repeat when we can't tell whether the server acted.
+	idempotent := method == http.MethodGet
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.sendOnce(ctx, method, fullURL, body, contentType)
+		retriesLeft := attempt < c.MaxRetries
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			// Give up on network errors unless the request can be repeated.
+			if !idempotent || !retriesLeft || ctx.Err() != nil {
+				return response, err
+			}
+			wait = backoff(attempt)
+		case response.StatusCode == http.StatusTooManyRequests:
+			// A 429 was never processed, so anything can be retried after the reset.
+			if !retriesLeft {
+				return response, nil
+			}
+			wait = c.rateLimitWait(response.Header, attempt)
+		case response.StatusCode >= 500 && idempotent && retriesLeft:
+			wait = backoff(attempt)
+		default:
+			return response, nil
+		}
+
+		err = sleepContext(ctx, wait)
+		if err != nil {
+			return response, err
+		}
+	}
+}
+
+// Function to make a single request and read the whole response.
+func (c *Client) sendOnce(ctx context.Context, method string, fullURL string, body []byte, contentType string) (rawResponse, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return rawResponse{}, err
+	}
+	if c.Token != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.Token))
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return rawResponse{}, err
+	}
+
+	defer response.Body.Close()
+	respData, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return rawResponse{}, err
+	}
+
+	// Keep track of how much of the quota is left.
+	c.updateRateLimit(response.Header)
+
+	return rawResponse{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       respData,
+	}, nil
+}
+
+// Function to GET an endpoint.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	_, err := c.do(ctx, http.MethodGet, path, params, nil, out)
+	return err
+}
+
+// Function to GET a page of results and the cursors to the pages around it.
+func (c *Client) getPage(ctx context.Context, path string, params url.Values, out interface{}) (Page, error) {
+	header, err := c.do(ctx, http.MethodGet, path, params, nil, out)
+	if err != nil {
+		return Page{}, err
+	}
+	return parseLink(header.Get("Link")), nil
+}
+
+// Function to POST to an endpoint.
+func (c *Client) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	_, err := c.do(ctx, http.MethodPost, path, nil, body, out)
+	return err
+}
+
+// Function to PUT to an endpoint.
+func (c *Client) put(ctx context.Context, path string, body interface{}, out interface{}) error {
+	_, err := c.do(ctx, http.MethodPut, path, nil, body, out)
+	return err
+}
+
+// Function to DELETE an endpoint.
+func (c *Client) delete(ctx context.Context, path string, out interface{}) error {
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil, out)
+	return err
+}
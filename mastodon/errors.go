@@ -0,0 +1,44 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Struct for an error response from the API.
+type APIError struct {
+	// HTTP status code, e.g. 401.
+	StatusCode int `json:"-"`
+	// HTTP method and path of the request that failed.
+	Method string `json:"-"`
+	Path   string `json:"-"`
+	// Mastodon's own description of what went wrong, if it sent one.
+	Message     string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Function to describe the error.
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	if e.Description != "" {
+		message = fmt.Sprintf("%v: %v", message, e.Description)
+	}
+	return fmt.Sprintf("%v %v returned %v: %v", e.Method, e.Path, e.StatusCode, message)
+}
+
+// Function to build an APIError from a response that wasn't 2xx.
+func newAPIError(method string, path string, statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Method:     method,
+		Path:       path,
+	}
+
+	// The body is usually {"error": "..."} but proxies can send HTML, so ignore anything else.
+	json.Unmarshal(body, apiErr)
+	return apiErr
+}
@@ -0,0 +1,32 @@
+package mastodon
+
+import (
+	"context"
+)
+
+// Struct for the parts of the instance information the client uses.
+type Instance struct {
+	Domain        string `json:"domain"`
+	Title         string `json:"title"`
+	Version       string `json:"version"`
+	Configuration struct {
+		Statuses struct {
+			MaxCharacters            int `json:"max_characters"`
+			MaxMediaAttachments      int `json:"max_media_attachments"`
+			CharactersReservedPerURL int `json:"characters_reserved_per_url"`
+		} `json:"statuses"`
+		Polls struct {
+			MaxOptions             int `json:"max_options"`
+			MaxCharactersPerOption int `json:"max_characters_per_option"`
+			MinExpiration          int `json:"min_expiration"`
+			MaxExpiration          int `json:"max_expiration"`
+		} `json:"polls"`
+	} `json:"configuration"`
+}
+
+// Function to get information about the instance.
+func (c *Client) GetInstance(ctx context.Context) (Instance, error) {
+	var instance Instance
+	err := c.get(ctx, "/api/v2/instance", nil, &instance)
+	return instance, err
+}
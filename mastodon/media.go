@@ -0,0 +1,83 @@
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How often to check on media that's still processing.
+const mediaPollInterval = time.Second
+
+// Struct for the options when uploading media.
+type MediaParams struct {
+	// Path to the image, video or audio file.
+	Path string
+	// Alt text describing the media.
+	Description string
+	// Focal point as "x,y" with each between -1.0 and 1.0. Empty means the centre.
+	Focus string
+}
+
+// Function to upload a file. Large files may still be processing when this returns.
+func (c *Client) UploadMedia(ctx context.Context, params MediaParams) (MediaAttachment, error) {
+	// Build the multipart form with the file and its details.
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := os.Open(params.Path)
+	if err != nil {
+		return MediaAttachment{}, err
+	}
+	defer file.Close()
+	part, err := form.CreateFormFile("file", filepath.Base(params.Path))
+	if err != nil {
+		return MediaAttachment{}, err
+	}
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return MediaAttachment{}, err
+	}
+	if params.Description != "" {
+		form.WriteField("description", params.Description)
+	}
+	if params.Focus != "" {
+		form.WriteField("focus", params.Focus)
+	}
+	err = form.Close()
+	if err != nil {
+		return MediaAttachment{}, err
+	}
+
+	var media MediaAttachment
+	path := "/api/v2/media"
+	_, err = c.roundTrip(ctx, http.MethodPost, path, c.Instance+path, body.Bytes(), form.FormDataContentType(), &media)
+	return media, err
+}
+
+// Function to wait until uploaded media has finished processing.
+func (c *Client) WaitForMedia(ctx context.Context, media MediaAttachment) (MediaAttachment, error) {
+	path := fmt.Sprintf("/api/v1/media/%v", url.PathEscape(media.ID))
+	for media.URL == "" {
+		err := sleepContext(ctx, mediaPollInterval)
+		if err != nil {
+			return media, err
+		}
+
+		// The instance answers 206 until it's done, then 200 with the URL.
+		response, err := c.roundTrip(ctx, http.MethodGet, path, c.Instance+path, nil, "", &media)
+		if err != nil {
+			return media, err
+		}
+		if response.StatusCode == http.StatusOK && media.URL == "" {
+			return media, fmt.Errorf("media %v finished processing without a URL", media.ID)
+		}
+	}
+	return media, nil
+}
@@ -0,0 +1,51 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Redirect URI that makes the instance display the code instead of redirecting.
+const OOBRedirectURI = "urn:ietf:wg:oauth:2.0:oob"
+
+// Function to register an application on the instance.
+func (c *Client) RegisterApp(ctx context.Context, name string, website string, scopes string) (RegisteredApp, error) {
+	// Create the map for the form data.
+	formData := make(map[string]string)
+	formData["client_name"] = name
+	formData["redirect_uris"] = OOBRedirectURI
+	formData["scopes"] = scopes
+	formData["website"] = website
+
+	var app RegisteredApp
+	err := c.post(ctx, "/api/v1/apps", formData, &app)
+	return app, err
+}
+
+// Function to build the URL the user visits to authorize an application.
+func (c *Client) AuthorizeURL(app RegisteredApp, scopes string) string {
+	params := url.Values{}
+	params.Set("client_id", app.ClientID)
+	params.Set("redirect_uri", OOBRedirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", scopes)
+
+	return fmt.Sprintf("%v/oauth/authorize?%v", c.Instance, params.Encode())
+}
+
+// Function to exchange an authorization code for an access token.
+func (c *Client) ExchangeCode(ctx context.Context, app RegisteredApp, code string, scopes string) (OAuthToken, error) {
+	// Create the map for the form data.
+	formData := make(map[string]string)
+	formData["grant_type"] = "authorization_code"
+	formData["code"] = code
+	formData["client_id"] = app.ClientID
+	formData["client_secret"] = app.ClientSecret
+	formData["redirect_uri"] = OOBRedirectURI
+	formData["scope"] = scopes
+
+	var token OAuthToken
+	err := c.post(ctx, "/oauth/token", formData, &token)
+	return token, err
+}
@@ -0,0 +1,105 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Function to start a stand-in instance that handles the OAuth endpoints.
+func newOAuthServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/apps", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("apps: got method %v, want POST", r.Method)
+		}
+		var form map[string]string
+		err := json.NewDecoder(r.Body).Decode(&form)
+		if err != nil {
+			t.Errorf("apps: could not decode body: %v", err)
+		}
+		if form["client_name"] != "GoToot" || form["redirect_uris"] != OOBRedirectURI || form["scopes"] != "read write" {
+			t.Errorf("apps: unexpected form %v", form)
+		}
+		w.Write([]byte(`{"id":"1","name":"GoToot","client_id":"cid","client_secret":"csecret"}`))
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		var form map[string]string
+		err := json.NewDecoder(r.Body).Decode(&form)
+		if err != nil {
+			t.Errorf("token: could not decode body: %v", err)
+		}
+		if form["code"] != "good" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		if form["grant_type"] != "authorization_code" || form["client_id"] != "cid" || form["client_secret"] != "csecret" || form["redirect_uri"] != OOBRedirectURI {
+			t.Errorf("token: unexpected form %v", form)
+		}
+		w.Write([]byte(`{"access_token":"tok","token_type":"Bearer","scope":"read write","created_at":1}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRegisterApp(t *testing.T) {
+	server := newOAuthServer(t)
+	client := NewClient(server.URL, "")
+
+	app, err := client.RegisterApp(context.Background(), "GoToot", "https://example.com", "read write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.ClientID != "cid" || app.ClientSecret != "csecret" {
+		t.Errorf("got %+v", app)
+	}
+}
+
+func TestAuthorizeURL(t *testing.T) {
+	client := NewClient("https://example.com", "")
+	raw := client.AuthorizeURL(RegisteredApp{ClientID: "cid"}, "read write")
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Scheme != "https" || parsed.Host != "example.com" || parsed.Path != "/oauth/authorize" {
+		t.Errorf("got %v", raw)
+	}
+	want := url.Values{
+		"client_id":     {"cid"},
+		"redirect_uri":  {OOBRedirectURI},
+		"response_type": {"code"},
+		"scope":         {"read write"},
+	}
+	if parsed.Query().Encode() != want.Encode() {
+		t.Errorf("got query %v, want %v", parsed.Query().Encode(), want.Encode())
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	server := newOAuthServer(t)
+	client := NewClient(server.URL, "")
+	app := RegisteredApp{ClientID: "cid", ClientSecret: "csecret"}
+
+	token, err := client.ExchangeCode(context.Background(), app, "good", "read write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "tok" {
+		t.Errorf("got %+v", token)
+	}
+
+	// A rejected code comes back as an API error.
+	_, err = client.ExchangeCode(context.Background(), app, "bad", "read write")
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %v, want a 400 APIError", err)
+	}
+}
@@ -0,0 +1,75 @@
+package mastodon
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Struct for the cursors to the pages around a page of results.
+type Page struct {
+	// Pass as MaxID to get the next older page.
+	MaxID string
+	// Pass as MinID to get the next newer page.
+	MinID string
+	// Pass as SinceID to get everything newer, newest first.
+	SinceID string
+}
+
+// Struct for the options when requesting a page of results.
+type PageParams struct {
+	MaxID   string
+	MinID   string
+	SinceID string
+	Limit   int
+}
+
+// Function to turn the page options into query parameters.
+func (p PageParams) values() url.Values {
+	params := url.Values{}
+	if p.MaxID != "" {
+		params.Set("max_id", p.MaxID)
+	}
+	if p.MinID != "" {
+		params.Set("min_id", p.MinID)
+	}
+	if p.SinceID != "" {
+		params.Set("since_id", p.SinceID)
+	}
+	if p.Limit > 0 {
+		params.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return params
+}
+
+// Function to parse the cursors out of a Link header.
+// It looks like: <https://host/api/v1/timelines/home?max_id=1>; rel="next", <...?min_id=2>; rel="prev"
+func parseLink(header string) Page {
+	var page Page
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		// Pull the query out of the URL between the angle brackets.
+		rawURL := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+		query := parsed.Query()
+
+		// The rel says which way the link points.
+		for _, param := range parts[1:] {
+			switch strings.TrimSpace(param) {
+			case `rel="next"`:
+				page.MaxID = query.Get("max_id")
+			case `rel="prev"`:
+				page.MinID = query.Get("min_id")
+				page.SinceID = query.Get("since_id")
+			}
+		}
+	}
+	return page
+}
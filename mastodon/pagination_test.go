@@ -0,0 +1,29 @@
+package mastodon
+
+import (
+	"testing"
+)
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Page
+	}{
+		{
+			`<https://mastodon.example/api/v1/timelines/home?max_id=109000000000000001>; rel="next", <https://mastodon.example/api/v1/timelines/home?min_id=109000000000000040>; rel="prev"`,
+			Page{MaxID: "109000000000000001", MinID: "109000000000000040"},
+		},
+		{
+			`<https://mastodon.example/api/v1/notifications?since_id=77>; rel="prev"`,
+			Page{SinceID: "77"},
+		},
+		{"", Page{}},
+		{"not a link header", Page{}},
+	}
+	for _, test := range tests {
+		got := parseLink(test.header)
+		if got != test.want {
+			t.Errorf("parseLink(%q) = %+v, want %+v", test.header, got, test.want)
+		}
+	}
+}
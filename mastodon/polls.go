@@ -0,0 +1,44 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Struct for the parameters of a new poll.
+type PollParams struct {
+	Options []string
+	// How long it runs for, in seconds.
+	ExpiresIn int
+	// Whether more than one option can be chosen.
+	Multiple bool
+	// Whether the totals are hidden until it ends.
+	HideTotals bool
+}
+
+// Function to turn the poll parameters into the form the API takes.
+func (p PollParams) values() map[string]interface{} {
+	return map[string]interface{}{
+		"options":     p.Options,
+		"expires_in":  p.ExpiresIn,
+		"multiple":    p.Multiple,
+		"hide_totals": p.HideTotals,
+	}
+}
+
+// Function to get the current state of a poll.
+func (c *Client) GetPoll(ctx context.Context, id string) (Poll, error) {
+	var poll Poll
+	err := c.get(ctx, fmt.Sprintf("/api/v1/polls/%v", url.PathEscape(id)), nil, &poll)
+	return poll, err
+}
+
+// Function to vote in a poll. Choices are indexes into the options, starting at 0.
+func (c *Client) Vote(ctx context.Context, id string, choices []int) (Poll, error) {
+	formData := map[string]interface{}{"choices": choices}
+
+	var poll Poll
+	err := c.post(ctx, fmt.Sprintf("/api/v1/polls/%v/votes", url.PathEscape(id)), formData, &poll)
+	return poll, err
+}
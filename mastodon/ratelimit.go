@@ -0,0 +1,97 @@
+package mastodon
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Longest the client will wait between retries.
+const maxBackoff = 30 * time.Second
+
+// Wait before the first retry, doubled for each one after. Tests shorten it.
+var backoffBase = 500 * time.Millisecond
+
+// Struct for the rate limit reported by the last response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	// False until a response has carried the headers.
+	Known bool
+}
+
+// Function to get the rate limit from the last response.
+func (c *Client) RateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+// Function to record the X-RateLimit headers from a response.
+func (c *Client) updateRateLimit(header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := time.Parse(time.RFC3339Nano, header.Get("X-RateLimit-Reset"))
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     reset,
+		Known:     true,
+	}
+}
+
+// Function to work out how long to wait after a 429.
+func (c *Client) rateLimitWait(header http.Header, attempt int) time.Duration {
+	// Retry-After is the most direct answer.
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	// Otherwise wait for the window to reset.
+	if limit := c.RateLimit(); limit.Known {
+		if wait := time.Until(limit.Reset); wait > 0 {
+			return wait
+		}
+	}
+
+	return backoff(attempt)
+}
+
+// Function to get a jittered exponential backoff for a retry attempt.
+func backoff(attempt int) time.Duration {
+	wait := backoffBase << uint(attempt)
+	if wait > maxBackoff || wait <= 0 {
+		wait = maxBackoff
+	}
+
+	// Somewhere between half and all of it, so scripts don't retry in lockstep.
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// Function to sleep unless the context is cancelled first.
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package mastodon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Function to make retries quick for a test.
+func fastBackoff(t *testing.T) {
+	old := backoffBase
+	backoffBase = time.Millisecond
+	t.Cleanup(func() { backoffBase = old })
+}
+
+// Function to start a server that answers with each status in turn, then 200s, counting the requests.
+func newSequenceServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, func() int) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		count := requests
+		mu.Unlock()
+
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		if count <= len(statuses) {
+			w.WriteHeader(statuses[count-1])
+			w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"1","acct":"someone"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestRetryAfter429(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "0")
+	server, requests := newSequenceServer(t, header, http.StatusTooManyRequests)
+	client := NewClient(server.URL, "tok")
+
+	// A 429 was never processed, so even a POST can go again.
+	var account Account
+	err := client.post(context.Background(), "/api/v1/statuses", map[string]string{"status": "hi"}, &account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Acct != "someone" || requests() != 2 {
+		t.Errorf("got %+v after %v requests, want 2", account, requests())
+	}
+}
+
+func TestRetry429GivesUp(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "0")
+	server, requests := newSequenceServer(t, header, 429, 429, 429, 429, 429)
+	client := NewClient(server.URL, "tok")
+
+	_, err := client.VerifyCredentials(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got %v, want a 429 APIError", err)
+	}
+	if requests() != client.MaxRetries+1 {
+		t.Errorf("made %v requests, want %v", requests(), client.MaxRetries+1)
+	}
+}
+
+func TestPostNotRetriedOn500(t *testing.T) {
+	fastBackoff(t)
+	server, requests := newSequenceServer(t, nil, http.StatusInternalServerError)
+	client := NewClient(server.URL, "tok")
+
+	// The server may have posted it, so trying again could post it twice.
+	err := client.post(context.Background(), "/api/v1/statuses", map[string]string{"status": "hi"}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got %v, want a 500 APIError", err)
+	}
+	if requests() != 1 {
+		t.Errorf("made %v requests, want 1", requests())
+	}
+}
+
+func TestGetRetriedOn5xx(t *testing.T) {
+	fastBackoff(t)
+	server, requests := newSequenceServer(t, nil, 503, 503, 503)
+	client := NewClient(server.URL, "tok")
+
+	account, err := client.VerifyCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Acct != "someone" || requests() != 4 {
+		t.Errorf("got %+v after %v requests, want 4", account, requests())
+	}
+
+	// One more failure than there are retries is an error.
+	server, requests = newSequenceServer(t, nil, 503, 503, 503, 503)
+	client = NewClient(server.URL, "tok")
+	_, err = client.VerifyCredentials(context.Background())
+	if err == nil || requests() != 4 {
+		t.Errorf("got %v after %v requests, want an error after 4", err, requests())
+	}
+}
+
+func TestUpdateRateLimit(t *testing.T) {
+	client := NewClient("https://example.com", "tok")
+	if client.RateLimit().Known {
+		t.Error("rate limit known before any response")
+	}
+
+	// Anything missing or malformed is ignored.
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "300")
+	header.Set("X-RateLimit-Remaining", "lots")
+	header.Set("X-RateLimit-Reset", "2026-10-17T10:05:00.000Z")
+	client.updateRateLimit(header)
+	if client.RateLimit().Known {
+		t.Error("rate limit known from a bad Remaining header")
+	}
+
+	header.Set("X-RateLimit-Remaining", "42")
+	client.updateRateLimit(header)
+	limit := client.RateLimit()
+	reset := time.Date(2026, 10, 17, 10, 5, 0, 0, time.UTC)
+	if !limit.Known || limit.Limit != 300 || limit.Remaining != 42 || !limit.Reset.Equal(reset) {
+		t.Errorf("got %+v", limit)
+	}
+}
+
+func TestRateLimitFromResponse(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "300")
+	header.Set("X-RateLimit-Remaining", "299")
+	header.Set("X-RateLimit-Reset", time.Now().Add(5*time.Minute).UTC().Format(time.RFC3339Nano))
+	server, _ := newSequenceServer(t, header)
+	client := NewClient(server.URL, "tok")
+
+	_, err := client.VerifyCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := client.RateLimit(); !limit.Known || limit.Remaining != 299 {
+		t.Errorf("got %+v", limit)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	fastBackoff(t)
+	client := NewClient("https://example.com", "tok")
+
+	// Retry-After wins.
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	if wait := client.rateLimitWait(header, 0); wait != 7*time.Second {
+		t.Errorf("got %v with Retry-After, want 7s", wait)
+	}
+
+	// Then the reset time.
+	reset := http.Header{}
+	reset.Set("X-RateLimit-Limit", "300")
+	reset.Set("X-RateLimit-Remaining", "0")
+	reset.Set("X-RateLimit-Reset", time.Now().Add(time.Minute).UTC().Format(time.RFC3339Nano))
+	client.updateRateLimit(reset)
+	if wait := client.rateLimitWait(http.Header{}, 0); wait < 58*time.Second || wait > time.Minute {
+		t.Errorf("got %v until the reset, want about a minute", wait)
+	}
+
+	// And a backoff when the reset has passed.
+	reset.Set("X-RateLimit-Reset", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano))
+	client.updateRateLimit(reset)
+	if wait := client.rateLimitWait(http.Header{}, 0); wait > time.Millisecond {
+		t.Errorf("got %v with the reset in the past, want the backoff", wait)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		full := backoffBase << uint(attempt)
+		if full > maxBackoff {
+			full = maxBackoff
+		}
+		wait := backoff(attempt)
+		if wait < full/2 || wait > full {
+			t.Errorf("attempt %v waited %v, want %v to %v", attempt, wait, full/2, full)
+		}
+	}
+}
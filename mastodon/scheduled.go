@@ -0,0 +1,62 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Struct for a status waiting to be posted.
+type ScheduledStatus struct {
+	ID          string    `json:"id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	Params      struct {
+		Text        string `json:"text"`
+		SpoilerText string `json:"spoiler_text"`
+		Sensitive   bool   `json:"sensitive"`
+		Visibility  string `json:"visibility"`
+		Language    string `json:"language"`
+		// Some versions send IDs and durations here as numbers, others as strings.
+		InReplyToID json.Number `json:"in_reply_to_id"`
+		MediaIDs    []string    `json:"media_ids"`
+		Poll        *struct {
+			Options   []string    `json:"options"`
+			ExpiresIn json.Number `json:"expires_in"`
+			Multiple  bool        `json:"multiple"`
+		} `json:"poll"`
+	} `json:"params"`
+	MediaAttachments []MediaAttachment `json:"media_attachments"`
+}
+
+// Function to schedule a status to be posted at a later time.
+func (c *Client) ScheduleStatus(ctx context.Context, params StatusParams, at time.Time) (ScheduledStatus, error) {
+	formData := params.values()
+	formData["scheduled_at"] = at.UTC().Format(time.RFC3339)
+
+	var scheduled ScheduledStatus
+	err := c.post(ctx, "/api/v1/statuses", formData, &scheduled)
+	return scheduled, err
+}
+
+// Function to list the statuses waiting to be posted.
+func (c *Client) ScheduledStatuses(ctx context.Context) ([]ScheduledStatus, error) {
+	var scheduled []ScheduledStatus
+	err := c.get(ctx, "/api/v1/scheduled_statuses", nil, &scheduled)
+	return scheduled, err
+}
+
+// Function to move a scheduled status to a new time.
+func (c *Client) RescheduleStatus(ctx context.Context, id string, at time.Time) (ScheduledStatus, error) {
+	formData := map[string]interface{}{"scheduled_at": at.UTC().Format(time.RFC3339)}
+
+	var scheduled ScheduledStatus
+	err := c.put(ctx, fmt.Sprintf("/api/v1/scheduled_statuses/%v", url.PathEscape(id)), formData, &scheduled)
+	return scheduled, err
+}
+
+// Function to cancel a scheduled status.
+func (c *Client) CancelScheduledStatus(ctx context.Context, id string) error {
+	return c.delete(ctx, fmt.Sprintf("/api/v1/scheduled_statuses/%v", url.PathEscape(id)), nil)
+}
@@ -0,0 +1,211 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Struct for the options when posting a status.
+type StatusParams struct {
+	Status      string
+	InReplyToID string
+	// Marks attached media as sensitive. Always set when there's a CW.
+	Sensitive   bool
+	SpoilerText string
+	// public, unlisted, private or direct. Empty uses the account default.
+	Visibility string
+	// ISO 639 language code. Empty lets the instance guess.
+	Language string
+	// IDs of media uploaded with UploadMedia.
+	MediaIDs []string
+	// A poll to attach instead of media.
+	Poll *PollParams
+}
+
+// Function to verify the token belongs to a registered application.
+func (c *Client) VerifyAppCredentials(ctx context.Context) (Application, error) {
+	var app Application
+	err := c.get(ctx, "/api/v1/apps/verify_credentials", nil, &app)
+	return app, err
+}
+
+// Function to get the account the token belongs to.
+func (c *Client) VerifyCredentials(ctx context.Context) (CredentialAccount, error) {
+	var user CredentialAccount
+	err := c.get(ctx, "/api/v1/accounts/verify_credentials", nil, &user)
+	return user, err
+}
+
+// Function to get a page of the home timeline.
+func (c *Client) HomeTimeline(ctx context.Context, page PageParams) ([]Status, Page, error) {
+	var toots []Status
+	cursors, err := c.getPage(ctx, "/api/v1/timelines/home", page.values(), &toots)
+	return toots, cursors, err
+}
+
+// Function to get a page of the public timeline, optionally only this instance.
+func (c *Client) PublicTimeline(ctx context.Context, local bool, page PageParams) ([]Status, Page, error) {
+	params := page.values()
+	if local {
+		params.Set("local", "true")
+	}
+
+	var toots []Status
+	cursors, err := c.getPage(ctx, "/api/v1/timelines/public", params, &toots)
+	return toots, cursors, err
+}
+
+// Function to get a page of notifications.
+func (c *Client) Notifications(ctx context.Context, page PageParams) ([]Notification, Page, error) {
+	var notes []Notification
+	cursors, err := c.getPage(ctx, "/api/v1/notifications", page.values(), &notes)
+	return notes, cursors, err
+}
+
+// Function to turn the status parameters into the form the API takes.
+func (p StatusParams) values() map[string]interface{} {
+	// Create the map for the form data.
+	formData := make(map[string]interface{})
+	formData["status"] = p.Status
+	if len(p.MediaIDs) > 0 {
+		formData["media_ids"] = p.MediaIDs
+	}
+	if p.InReplyToID != "" {
+		formData["in_reply_to_id"] = p.InReplyToID
+	}
+	if p.Sensitive || p.SpoilerText != "" {
+		formData["sensitive"] = "true"
+	}
+	if p.SpoilerText != "" {
+		formData["spoiler_text"] = p.SpoilerText
+	}
+	if p.Visibility != "" {
+		formData["visibility"] = p.Visibility
+	}
+	if p.Language != "" {
+		formData["language"] = p.Language
+	}
+	if p.Poll != nil {
+		formData["poll"] = p.Poll.values()
+	}
+	return formData
+}
+
+// Function to post a status.
+func (c *Client) PostStatus(ctx context.Context, params StatusParams) (Status, error) {
+	formData := params.values()
+
+	var posted Status
+	err := c.post(ctx, "/api/v1/statuses", formData, &posted)
+	return posted, err
+}
+
+// Function to favourite a status.
+func (c *Client) Favourite(ctx context.Context, id string) (Status, error) {
+	return c.statusAction(ctx, id, "favourite")
+}
+
+// Function to remove a favourite from a status.
+func (c *Client) Unfavourite(ctx context.Context, id string) (Status, error) {
+	return c.statusAction(ctx, id, "unfavourite")
+}
+
+// Function to boost a status, returning the status that was boosted.
+func (c *Client) Reblog(ctx context.Context, id string) (Status, error) {
+	var boost Status
+	err := c.post(ctx, fmt.Sprintf("/api/v1/statuses/%v/reblog", url.PathEscape(id)), nil, &boost)
+
+	// The response is our new boost wrapping the original, which has the state we care about.
+	if err == nil && boost.Reblog != nil {
+		return *boost.Reblog, nil
+	}
+	return boost, err
+}
+
+// Function to undo a boost.
+func (c *Client) Unreblog(ctx context.Context, id string) (Status, error) {
+	return c.statusAction(ctx, id, "unreblog")
+}
+
+// Function to bookmark a status.
+func (c *Client) Bookmark(ctx context.Context, id string) (Status, error) {
+	return c.statusAction(ctx, id, "bookmark")
+}
+
+// Function to remove a bookmark.
+func (c *Client) Unbookmark(ctx context.Context, id string) (Status, error) {
+	return c.statusAction(ctx, id, "unbookmark")
+}
+
+// Function to run one of the POST /statuses/:id/<action> endpoints.
+func (c *Client) statusAction(ctx context.Context, id string, action string) (Status, error) {
+	var toot Status
+	err := c.post(ctx, fmt.Sprintf("/api/v1/statuses/%v/%v", url.PathEscape(id), action), nil, &toot)
+	return toot, err
+}
+
+// Struct for the toots around a status in its thread.
+type Context struct {
+	Ancestors   []Status `json:"ancestors"`
+	Descendants []Status `json:"descendants"`
+}
+
+// Function to get a single status.
+func (c *Client) GetStatus(ctx context.Context, id string) (Status, error) {
+	var toot Status
+	err := c.get(ctx, fmt.Sprintf("/api/v1/statuses/%v", url.PathEscape(id)), nil, &toot)
+	return toot, err
+}
+
+// Function to get what a status replies to and the replies to it.
+func (c *Client) StatusContext(ctx context.Context, id string) (Context, error) {
+	var thread Context
+	err := c.get(ctx, fmt.Sprintf("/api/v1/statuses/%v/context", url.PathEscape(id)), nil, &thread)
+	return thread, err
+}
+
+// Function to delete one of our statuses. The response includes the source text for redrafting.
+func (c *Client) DeleteStatus(ctx context.Context, id string) (Status, error) {
+	var toot Status
+	err := c.delete(ctx, fmt.Sprintf("/api/v1/statuses/%v", url.PathEscape(id)), &toot)
+	return toot, err
+}
+
+// Function to get the source text of one of our statuses.
+func (c *Client) GetStatusSource(ctx context.Context, id string) (StatusSource, error) {
+	var source StatusSource
+	err := c.get(ctx, fmt.Sprintf("/api/v1/statuses/%v/source", url.PathEscape(id)), nil, &source)
+	return source, err
+}
+
+// Function to edit one of our statuses. Visibility and the reply target can't be changed.
+func (c *Client) EditStatus(ctx context.Context, id string, params StatusParams) (Status, error) {
+	// Create the map for the form data.
+	formData := make(map[string]interface{})
+	formData["status"] = params.Status
+	formData["spoiler_text"] = params.SpoilerText
+	if len(params.MediaIDs) > 0 {
+		formData["media_ids"] = params.MediaIDs
+	}
+	if params.Sensitive || params.SpoilerText != "" {
+		formData["sensitive"] = "true"
+	}
+	if params.Language != "" {
+		formData["language"] = params.Language
+	}
+	if params.Poll != nil {
+		formData["poll"] = params.Poll.values()
+	}
+
+	var edited Status
+	err := c.put(ctx, fmt.Sprintf("/api/v1/statuses/%v", url.PathEscape(id)), formData, &edited)
+	return edited, err
+}
+
+// Function to get every revision of a status, oldest first.
+func (c *Client) StatusHistory(ctx context.Context, id string) ([]StatusEdit, error) {
+	var history []StatusEdit
+	err := c.get(ctx, fmt.Sprintf("/api/v1/statuses/%v/history", url.PathEscape(id)), nil, &history)
+	return history, err
+}
@@ -0,0 +1,255 @@
+package mastodon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Streams that can be subscribed to.
+const (
+	StreamUser          = "user"
+	StreamNotifications = "user:notification"
+	StreamPublic        = "public"
+	StreamLocal         = "public:local"
+	StreamHashtag       = "hashtag"
+	StreamList          = "list"
+)
+
+// Struct for which stream to follow.
+type StreamParams struct {
+	// One of the Stream constants.
+	Stream string
+	// Hashtag to follow, without the #, for StreamHashtag.
+	Tag string
+	// List ID to follow for StreamList.
+	List string
+	// Called when the connection drops, before waiting to reconnect.
+	OnDisconnect func(err error, wait time.Duration)
+}
+
+// Struct for an event received from the stream.
+type StreamEvent struct {
+	// update, notification, delete, status.update or anything newer.
+	Event string
+	// Set for update and status.update.
+	Status *Status
+	// Set for notification.
+	Notification *Notification
+	// Set for delete.
+	DeletedID string
+}
+
+// Struct for a message on the WebSocket stream.
+type streamMessage struct {
+	Stream  []string `json:"stream"`
+	Event   string   `json:"event"`
+	Payload string   `json:"payload"`
+}
+
+// How long a connection has to stay up before the backoff starts over.
+const streamHealthyAfter = time.Minute
+
+// Function to follow a stream until the context is cancelled, reconnecting when it drops.
+func (c *Client) Stream(ctx context.Context, params StreamParams, handler func(StreamEvent)) error {
+	attempt := 0
+	for {
+		started := time.Now()
+		err := c.streamOnce(ctx, params, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// A bad token or stream name won't fix itself.
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests {
+			return err
+		}
+
+		if time.Since(started) > streamHealthyAfter {
+			attempt = 0
+		}
+		wait := backoff(attempt)
+		attempt++
+		if params.OnDisconnect != nil {
+			params.OnDisconnect(err, wait)
+		}
+
+		err = sleepContext(ctx, wait)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Function to connect to the stream once, preferring WebSocket and falling back to server-sent events.
+func (c *Client) streamOnce(ctx context.Context, params StreamParams, handler func(StreamEvent)) error {
+	base, err := c.streamingBase(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.streamWebSocket(ctx, base, params, handler)
+	if errors.Is(err, errWebSocketRefused) {
+		return c.streamEventSource(ctx, base, params, handler)
+	}
+	return err
+}
+
+// Function to find the streaming server, which may not be on the same host as the API.
+func (c *Client) streamingBase(ctx context.Context) (string, error) {
+	var instance struct {
+		URLs struct {
+			StreamingAPI string `json:"streaming_api"`
+		} `json:"urls"`
+	}
+	err := c.get(ctx, "/api/v1/instance", nil, &instance)
+	if err != nil {
+		return "", err
+	}
+	if instance.URLs.StreamingAPI == "" {
+		return c.Instance, nil
+	}
+
+	// It's given as wss://, but the event stream needs https://.
+	base := strings.Replace(instance.URLs.StreamingAPI, "wss://", "https://", 1)
+	base = strings.Replace(base, "ws://", "http://", 1)
+	return strings.TrimRight(base, "/"), nil
+}
+
+// Function to build the stream name and parameters shared by both transports.
+func (p StreamParams) values() url.Values {
+	values := url.Values{}
+	values.Set("stream", p.Stream)
+	if p.Stream == StreamHashtag {
+		values.Set("tag", p.Tag)
+	}
+	if p.Stream == StreamList {
+		values.Set("list", p.List)
+	}
+	return values
+}
+
+// Function to follow the stream over WebSocket.
+func (c *Client) streamWebSocket(ctx context.Context, base string, params StreamParams, handler func(StreamEvent)) error {
+	header := http.Header{}
+	if c.Token != "" {
+		header.Set("Authorization", fmt.Sprintf("Bearer %v", c.Token))
+	}
+
+	wsURL := fmt.Sprintf("%v/api/v1/streaming?%v", websocketScheme(base), params.values().Encode())
+	conn, err := dialWebSocket(ctx, wsURL, header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read when the context is cancelled.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		raw, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var message streamMessage
+		err = json.Unmarshal(raw, &message)
+		if err != nil {
+			continue
+		}
+		event, ok := decodeStreamEvent(message.Event, message.Payload)
+		if ok {
+			handler(event)
+		}
+	}
+}
+
+// Function to follow the stream as server-sent events.
+func (c *Client) streamEventSource(ctx context.Context, base string, params StreamParams, handler func(StreamEvent)) error {
+	// Each stream has its own path here.
+	path := "/api/v1/streaming/" + strings.Replace(params.Stream, ":", "/", -1)
+	query := params.values()
+	query.Del("stream")
+	fullURL := base + path
+	if len(query) > 0 {
+		fullURL = fmt.Sprintf("%v?%v", fullURL, query.Encode())
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.Token))
+	}
+	request.Header.Set("Accept", "text/event-stream")
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return newAPIError(http.MethodGet, path, response.StatusCode, nil)
+	}
+
+	// Events are "event:" and "data:" lines ending with a blank line.
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	var eventName string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if eventName != "" {
+				event, ok := decodeStreamEvent(eventName, strings.Join(data, "\n"))
+				if ok {
+					handler(event)
+				}
+			}
+			eventName = ""
+			data = nil
+		case strings.HasPrefix(line, ":"):
+			// Heartbeat.
+		case strings.HasPrefix(line, "event:"):
+			eventName = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("stream closed by server")
+}
+
+// Function to turn an event name and payload into a StreamEvent.
+func decodeStreamEvent(name string, payload string) (StreamEvent, bool) {
+	event := StreamEvent{Event: name}
+	switch name {
+	case "update", "status.update":
+		var toot Status
+		if json.Unmarshal([]byte(payload), &toot) != nil {
+			return event, false
+		}
+		event.Status = &toot
+	case "notification":
+		var note Notification
+		if json.Unmarshal([]byte(payload), &note) != nil {
+			return event, false
+		}
+		event.Notification = &note
+	case "delete":
+		event.DeletedID = strings.Trim(payload, `"`)
+	}
+	return event, true
+}
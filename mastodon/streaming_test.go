@@ -0,0 +1,361 @@
+package mastodon
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Payloads for each kind of event the stream sends.
+const (
+	testStatusPayload       = `{"id":"101","content":"<p>hello</p>","account":{"id":"1","acct":"someone"}}`
+	testNotificationPayload = `{"id":"201","type":"favourite","account":{"id":"2","acct":"fan"},"status":{"id":"101"}}`
+)
+
+// Function to work out the Sec-WebSocket-Accept a server should send for a key.
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Function to take over a request and finish the upgrade, as the server side of the handshake.
+// It runs in the server's goroutine, so it panics rather than calling t.Fatal.
+func acceptWebSocket(w http.ResponseWriter, accept string) *websocketConn {
+	conn, buffered, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(buffered, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %v\r\n\r\n", accept)
+	buffered.Flush()
+	return &websocketConn{conn: conn, reader: buffered.Reader}
+}
+
+// Function to write an unmasked frame, as servers do.
+func writeServerFrame(t *testing.T, conn net.Conn, final bool, opcode byte, payload []byte) {
+	first := opcode
+	if final {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	if len(payload) < 126 {
+		frame = append(frame, byte(len(payload)))
+	} else {
+		frame = append(frame, 126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	}
+	frame = append(frame, payload...)
+
+	_, err := conn.Write(frame)
+	if err != nil {
+		t.Errorf("could not write frame: %v", err)
+	}
+}
+
+// Function to write a whole stream message in one frame.
+func writeStreamMessage(t *testing.T, conn net.Conn, event string, payload string) {
+	message, _ := json.Marshal(streamMessage{Stream: []string{StreamUser}, Event: event, Payload: payload})
+	writeServerFrame(t, conn, true, opText, message)
+}
+
+// Function to start a stand-in instance whose streaming endpoint runs ws on each WebSocket connection.
+// With no ws, upgrades are refused and sse serves the event stream instead.
+func newStreamingServer(t *testing.T, ws func(conn *websocketConn), sse http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/instance", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"urls":{}}`))
+	})
+	mux.HandleFunc("/api/v1/streaming", func(w http.ResponseWriter, r *http.Request) {
+		if ws == nil || r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "no websockets here", http.StatusBadRequest)
+			return
+		}
+		conn := acceptWebSocket(w, acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		defer conn.Close()
+		ws(conn)
+	})
+	if sse != nil {
+		mux.HandleFunc("/api/v1/streaming/", sse)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Function to block until the client hangs up.
+func waitForHangUp(conn *websocketConn) {
+	for {
+		_, _, _, err := conn.readFrame()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func TestDialWebSocketHandshake(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			t.Errorf("unexpected upgrade headers %v", r.Header)
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("got Authorization %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("stream") != "user" {
+			t.Errorf("got query %v", r.URL.RawQuery)
+		}
+		conn := acceptWebSocket(w, acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		defer conn.Close()
+		writeServerFrame(t, conn.conn, true, opText, []byte("hi"))
+		waitForHangUp(conn)
+	}))
+	defer server.Close()
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer tok")
+	conn, err := dialWebSocket(context.Background(), websocketScheme(server.URL)+"/?stream=user", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	message, err := conn.ReadMessage()
+	if err != nil || string(message) != "hi" {
+		t.Errorf("got %q, %v, want hi", message, err)
+	}
+}
+
+func TestDialWebSocketBadAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn := acceptWebSocket(w, acceptKey("some other key"))
+		defer conn.Close()
+		waitForHangUp(conn)
+	}))
+	defer server.Close()
+
+	_, err := dialWebSocket(context.Background(), websocketScheme(server.URL), http.Header{})
+	if !errors.Is(err, errWebSocketRefused) || !strings.Contains(err.Error(), "Sec-WebSocket-Accept") {
+		t.Errorf("got %v, want a bad Sec-WebSocket-Accept error", err)
+	}
+}
+
+func TestDialWebSocketRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := dialWebSocket(context.Background(), websocketScheme(server.URL), http.Header{})
+	if !errors.Is(err, errWebSocketRefused) {
+		t.Errorf("got %v, want errWebSocketRefused", err)
+	}
+}
+
+func TestWebSocketFragmentsPingAndClose(t *testing.T) {
+	pong := make(chan []byte, 1)
+	closed := make(chan byte, 1)
+	long := strings.Repeat("x", 300)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn := acceptWebSocket(w, acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		defer conn.Close()
+
+		// A message split over three frames with a ping in the middle.
+		writeServerFrame(t, conn.conn, false, opText, []byte("hel"))
+		writeServerFrame(t, conn.conn, true, opPing, []byte("are you there"))
+		writeServerFrame(t, conn.conn, false, opContinuation, []byte("lo "))
+		writeServerFrame(t, conn.conn, true, opContinuation, []byte(long))
+
+		_, opcode, payload, err := conn.readFrame()
+		if err != nil || opcode != opPong {
+			t.Errorf("got opcode %v, %v, want a pong", opcode, err)
+		}
+		pong <- payload
+
+		// Then say goodbye and wait for the client to answer.
+		writeServerFrame(t, conn.conn, true, opClose, nil)
+		_, opcode, _, err = conn.readFrame()
+		if err != nil {
+			t.Errorf("no close frame back: %v", err)
+		}
+		closed <- opcode
+	}))
+	defer server.Close()
+
+	conn, err := dialWebSocket(context.Background(), websocketScheme(server.URL), http.Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	message, err := conn.ReadMessage()
+	if err != nil || string(message) != "hello "+long {
+		t.Errorf("got %q, %v", message, err)
+	}
+	if payload := <-pong; string(payload) != "are you there" {
+		t.Errorf("pong carried %q, want the ping's payload", payload)
+	}
+
+	_, err = conn.ReadMessage()
+	if err != io.EOF {
+		t.Errorf("got %v after close, want io.EOF", err)
+	}
+	if opcode := <-closed; opcode != opClose {
+		t.Errorf("client answered close with opcode %v", opcode)
+	}
+}
+
+func TestStreamWebSocketEvents(t *testing.T) {
+	server := newStreamingServer(t, func(conn *websocketConn) {
+		writeStreamMessage(t, conn.conn, "update", testStatusPayload)
+		writeStreamMessage(t, conn.conn, "notification", testNotificationPayload)
+		writeStreamMessage(t, conn.conn, "delete", "101")
+		writeStreamMessage(t, conn.conn, "status.update", testStatusPayload)
+		waitForHangUp(conn)
+	}, nil)
+
+	events := collectEvents(t, NewClient(server.URL, "tok"), 4, nil)
+	checkEvents(t, events)
+}
+
+func TestStreamFallsBackToEventSource(t *testing.T) {
+	server := newStreamingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/streaming/user" {
+			t.Errorf("got path %v", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("got Authorization %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, ":thump\n\n")
+		fmt.Fprintf(w, "event: update\ndata: %v\n\n", testStatusPayload)
+		fmt.Fprintf(w, "event: notification\ndata: %v\n\n", testNotificationPayload)
+		fmt.Fprintf(w, "event: delete\ndata: 101\n\n")
+		fmt.Fprintf(w, "event: status.update\ndata: %v\n\n", testStatusPayload)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	events := collectEvents(t, NewClient(server.URL, "tok"), 4, nil)
+	checkEvents(t, events)
+}
+
+func TestStreamStopsOnClientError(t *testing.T) {
+	server := newStreamingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	client := NewClient(server.URL, "revoked")
+	params := StreamParams{
+		Stream: StreamUser,
+		OnDisconnect: func(err error, wait time.Duration) {
+			t.Errorf("reconnecting after %v", err)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := client.Stream(ctx, params, func(StreamEvent) {})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v, want a 401 APIError", err)
+	}
+}
+
+func TestStreamReconnects(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	server := newStreamingServer(t, func(conn *websocketConn) {
+		mu.Lock()
+		connections++
+		first := connections == 1
+		mu.Unlock()
+
+		// Drop the first connection without a close frame.
+		writeStreamMessage(t, conn.conn, "delete", "1")
+		if first {
+			return
+		}
+		waitForHangUp(conn)
+	}, nil)
+
+	var disconnects int
+	events := collectEvents(t, NewClient(server.URL, "tok"), 2, func(err error, wait time.Duration) {
+		disconnects++
+	})
+	if len(events) != 2 || disconnects != 1 {
+		t.Errorf("got %v events and %v disconnects, want 2 and 1", len(events), disconnects)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if connections != 2 {
+		t.Errorf("connected %v times, want 2", connections)
+	}
+}
+
+func TestDecodeStreamEvent(t *testing.T) {
+	_, ok := decodeStreamEvent("update", "not json")
+	if ok {
+		t.Error("a bad update payload was accepted")
+	}
+
+	// Events we don't know about are passed on by name.
+	event, ok := decodeStreamEvent("announcement", "{}")
+	if !ok || event.Event != "announcement" || event.Status != nil {
+		t.Errorf("got %+v, %v", event, ok)
+	}
+
+	event, ok = decodeStreamEvent("delete", `"102"`)
+	if !ok || event.DeletedID != "102" {
+		t.Errorf("got %+v, %v", event, ok)
+	}
+}
+
+// Function to follow the user stream until count events arrive.
+func collectEvents(t *testing.T, client *Client, count int, onDisconnect func(error, time.Duration)) []StreamEvent {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []StreamEvent
+	params := StreamParams{Stream: StreamUser, OnDisconnect: onDisconnect}
+	err := client.Stream(ctx, params, func(event StreamEvent) {
+		events = append(events, event)
+		if len(events) == count {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("stream ended with %v after %v events", err, len(events))
+	}
+	return events
+}
+
+// Function to check the events sent by the streaming tests were decoded.
+func checkEvents(t *testing.T, events []StreamEvent) {
+	if len(events) != 4 {
+		t.Fatalf("got %v events, want 4", len(events))
+	}
+	if events[0].Event != "update" || events[0].Status == nil || events[0].Status.ID != "101" || events[0].Status.Account.Acct != "someone" {
+		t.Errorf("update decoded as %+v", events[0])
+	}
+	if events[1].Event != "notification" || events[1].Notification == nil || events[1].Notification.Type != "favourite" || events[1].Notification.Account.Acct != "fan" {
+		t.Errorf("notification decoded as %+v", events[1])
+	}
+	if events[2].Event != "delete" || events[2].DeletedID != "101" {
+		t.Errorf("delete decoded as %+v", events[2])
+	}
+	if events[3].Event != "status.update" || events[3].Status == nil || events[3].Status.ID != "101" {
+		t.Errorf("status.update decoded as %+v", events[3])
+	}
+}
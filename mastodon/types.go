@@ -0,0 +1,231 @@
+package mastodon
+
+import (
+	"time"
+)
+
+// Struct for an account.
+type Account struct {
+	ID             string    `json:"id"`
+	Username       string    `json:"username"`
+	Acct           string    `json:"acct"`
+	DisplayName    string    `json:"display_name"`
+	Locked         bool      `json:"locked"`
+	Bot            bool      `json:"bot"`
+	Discoverable   bool      `json:"discoverable"`
+	Group          bool      `json:"group"`
+	CreatedAt      time.Time `json:"created_at"`
+	Note           string    `json:"note"`
+	URL            string    `json:"url"`
+	Avatar         string    `json:"avatar"`
+	AvatarStatic   string    `json:"avatar_static"`
+	Header         string    `json:"header"`
+	HeaderStatic   string    `json:"header_static"`
+	FollowersCount int       `json:"followers_count"`
+	FollowingCount int       `json:"following_count"`
+	StatusesCount  int       `json:"statuses_count"`
+	// Just the date, e.g. 2006-01-02.
+	LastStatusAt string  `json:"last_status_at"`
+	Emojis       []Emoji `json:"emojis"`
+	Fields       []Field `json:"fields"`
+}
+
+// Struct for a name and value shown on a profile.
+type Field struct {
+	Name       string     `json:"name"`
+	Value      string     `json:"value"`
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+// Struct for the user's own account, with the settings only they can see.
+type CredentialAccount struct {
+	Account
+	Source struct {
+		Privacy             string  `json:"privacy"`
+		Sensitive           bool    `json:"sensitive"`
+		Language            string  `json:"language"`
+		Note                string  `json:"note"`
+		Fields              []Field `json:"fields"`
+		FollowRequestsCount int     `json:"follow_requests_count"`
+	} `json:"source"`
+}
+
+// Struct for a status, called a toot in the rest of GoToot.
+type Status struct {
+	ID                 string            `json:"id"`
+	ClientID           int               `json:"client_id"`
+	CreatedAt          time.Time         `json:"created_at"`
+	InReplyToID        string            `json:"in_reply_to_id"`
+	InReplyToAccountID string            `json:"in_reply_to_account_id"`
+	Sensitive          bool              `json:"sensitive"`
+	SpoilerText        string            `json:"spoiler_text"`
+	Visibility         string            `json:"visibility"`
+	Language           string            `json:"language"`
+	URI                string            `json:"uri"`
+	URL                string            `json:"url"`
+	RepliesCount       int               `json:"replies_count"`
+	ReblogsCount       int               `json:"reblogs_count"`
+	FavouritesCount    int               `json:"favourites_count"`
+	Favourited         bool              `json:"favourited"`
+	Reblogged          bool              `json:"reblogged"`
+	Muted              bool              `json:"muted"`
+	Bookmarked         bool              `json:"bookmarked"`
+	Pinned             bool              `json:"pinned"`
+	Content            string            `json:"content"`
+	Text               string            `json:"text,omitempty"`
+	EditedAt           *time.Time        `json:"edited_at"`
+	Reblog             *Status           `json:"reblog"`
+	Application        *Application      `json:"application"`
+	Account            Account           `json:"account"`
+	MediaAttachments   []MediaAttachment `json:"media_attachments"`
+	Mentions           []Mention         `json:"mentions"`
+	Tags               []Tag             `json:"tags"`
+	Emojis             []Emoji           `json:"emojis"`
+	Card               *Card             `json:"card"`
+	Poll               *Poll             `json:"poll"`
+}
+
+// Struct for an image, video or audio file attached to a status.
+type MediaAttachment struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	URL         string    `json:"url"`
+	PreviewURL  string    `json:"preview_url"`
+	RemoteURL   string    `json:"remote_url"`
+	Meta        MediaMeta `json:"meta"`
+	Description string    `json:"description"`
+	Blurhash    string    `json:"blurhash"`
+}
+
+// Struct for what's known about an attachment's size and focus.
+type MediaMeta struct {
+	Original *MediaSize `json:"original"`
+	Small    *MediaSize `json:"small"`
+	Focus    *struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"focus"`
+}
+
+// Struct for the size of an attachment or its preview.
+type MediaSize struct {
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Aspect float64 `json:"aspect"`
+	// Seconds, for video and audio.
+	Duration float64 `json:"duration"`
+}
+
+// Struct for an account mentioned in a status.
+type Mention struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	URL      string `json:"url"`
+	Acct     string `json:"acct"`
+}
+
+// Struct for a hashtag used in a status.
+type Tag struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Struct for the preview of a link in a status.
+type Card struct {
+	URL          string `json:"url"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Type         string `json:"type"`
+	AuthorName   string `json:"author_name"`
+	AuthorURL    string `json:"author_url"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Image        string `json:"image"`
+	EmbedURL     string `json:"embed_url"`
+	Blurhash     string `json:"blurhash"`
+}
+
+// Struct for a custom emoji.
+type Emoji struct {
+	Shortcode       string `json:"shortcode"`
+	URL             string `json:"url"`
+	StaticURL       string `json:"static_url"`
+	VisibleInPicker bool   `json:"visible_in_picker"`
+	Category        string `json:"category"`
+}
+
+// Struct for a poll attached to a toot.
+type Poll struct {
+	ID          string       `json:"id"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+	Expired     bool         `json:"expired"`
+	Multiple    bool         `json:"multiple"`
+	VotesCount  int          `json:"votes_count"`
+	VotersCount *int         `json:"voters_count"`
+	Options     []PollOption `json:"options"`
+	Emojis      []Emoji      `json:"emojis"`
+	Voted       bool         `json:"voted"`
+	OwnVotes    []int        `json:"own_votes"`
+}
+
+// Struct for one of the choices in a poll.
+type PollOption struct {
+	Title string `json:"title"`
+	// Null while the totals are hidden.
+	VotesCount *int `json:"votes_count"`
+}
+
+// Struct for notifications.
+type Notification struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Account   Account   `json:"account"`
+	// The status it's about, if there is one.
+	Status *Status `json:"status"`
+}
+
+// Struct for the application registered with the instance.
+type RegisteredApp struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Website      string `json:"website"`
+	RedirectURI  string `json:"redirect_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	VapidKey     string `json:"vapid_key"`
+}
+
+// Struct for the token returned by the OAuth endpoint.
+type OAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// Struct for the application a token belongs to.
+type Application struct {
+	Name     string `json:"name"`
+	Website  string `json:"website"`
+	VapidKey string `json:"vapid_key"`
+}
+
+// Struct for the source text of a status, for editing.
+type StatusSource struct {
+	ID          string `json:"id"`
+	Text        string `json:"text"`
+	SpoilerText string `json:"spoiler_text"`
+}
+
+// Struct for one revision of an edited status.
+type StatusEdit struct {
+	Content     string    `json:"content"`
+	SpoilerText string    `json:"spoiler_text"`
+	Sensitive   bool      `json:"sensitive"`
+	CreatedAt   time.Time `json:"created_at"`
+	Account     Account   `json:"account"`
+}
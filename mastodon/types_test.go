@@ -0,0 +1,203 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Function to decode a recorded response, encode it again and check nothing the model covers was lost.
+// Fields the model doesn't have are ignored, and null is the same as the zero value.
+func roundTrip(t *testing.T, name string, out interface{}) {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal(raw, out)
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+
+	var want, got interface{}
+	json.Unmarshal(raw, &want)
+	json.Unmarshal(encoded, &got)
+	compareJSON(t, name, "", want, got)
+
+	// Encoding it a second time gives the same JSON, so nothing changes on the way through.
+	again := reflect.New(reflect.TypeOf(out).Elem()).Interface()
+	err = json.Unmarshal(encoded, again)
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+	reencoded, err := json.Marshal(again)
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+	if string(reencoded) != string(encoded) {
+		t.Errorf("%v: encoding again gave\n%s\nwant\n%s", name, reencoded, encoded)
+	}
+}
+
+// Function to check every value in got matches the one at the same path in want.
+func compareJSON(t *testing.T, name string, path string, want interface{}, got interface{}) {
+	t.Helper()
+	switch got := got.(type) {
+	case map[string]interface{}:
+		wantMap, ok := want.(map[string]interface{})
+		if !ok {
+			if want != nil || len(got) > 0 {
+				t.Errorf("%v%v: got an object, want %v", name, path, want)
+			}
+			return
+		}
+		for key, value := range got {
+			// GoToot's own numbering isn't part of the API.
+			if key == "client_id" {
+				continue
+			}
+			compareJSON(t, name, path+"."+key, wantMap[key], value)
+		}
+	case []interface{}:
+		wantSlice, _ := want.([]interface{})
+		if len(got) != len(wantSlice) {
+			t.Errorf("%v%v: got %v items, want %v", name, path, len(got), len(wantSlice))
+			return
+		}
+		for i := range got {
+			compareJSON(t, name, fmt.Sprintf("%v[%v]", path, i), wantSlice[i], got[i])
+		}
+	case string:
+		wantString, _ := want.(string)
+		if got == wantString {
+			return
+		}
+		// Times come back in Go's format rather than Mastodon's.
+		gotTime, gotErr := time.Parse(time.RFC3339Nano, got)
+		wantTime, wantErr := time.Parse(time.RFC3339Nano, wantString)
+		if gotErr != nil || wantErr != nil || !gotTime.Equal(wantTime) {
+			t.Errorf("%v%v: got %q, want %q", name, path, got, want)
+		}
+	case nil:
+		if want != nil {
+			t.Errorf("%v%v: got null, want %v", name, path, want)
+		}
+	default:
+		// Numbers and booleans. A missing or null value decodes to the zero value.
+		if want == nil {
+			want = reflect.Zero(reflect.TypeOf(got)).Interface()
+		}
+		if got != want {
+			t.Errorf("%v%v: got %v, want %v", name, path, got, want)
+		}
+	}
+}
+
+func TestStatusReblogRoundTrip(t *testing.T) {
+	var status Status
+	roundTrip(t, "status_reblog.json", &status)
+
+	if status.Reblog == nil {
+		t.Fatal("reblog wasn't decoded")
+	}
+	if status.Account.Acct != "carol" || status.Reblog.Account.Acct != "bob@fosstodon.example" {
+		t.Errorf("got booster %v and author %v", status.Account.Acct, status.Reblog.Account.Acct)
+	}
+	if status.Reblog.EditedAt == nil || status.EditedAt != nil {
+		t.Errorf("got edited_at %v and %v", status.EditedAt, status.Reblog.EditedAt)
+	}
+	if len(status.Reblog.Mentions) != 1 || status.Reblog.Mentions[0].Acct != "alice" || len(status.Reblog.Tags) != 1 {
+		t.Errorf("got mentions %+v and tags %+v", status.Reblog.Mentions, status.Reblog.Tags)
+	}
+}
+
+func TestStatusCardRoundTrip(t *testing.T) {
+	var status Status
+	roundTrip(t, "status_card.json", &status)
+
+	if status.Card == nil || status.Card.Title != "Golden files in Go" || status.Card.Width != 400 {
+		t.Errorf("got card %+v", status.Card)
+	}
+	if status.Application == nil || status.Application.Name != "Tusky" {
+		t.Errorf("got application %+v", status.Application)
+	}
+}
+
+func TestStatusPollRoundTrip(t *testing.T) {
+	var status Status
+	roundTrip(t, "status_poll.json", &status)
+
+	poll := status.Poll
+	if poll == nil {
+		t.Fatal("poll wasn't decoded")
+	}
+	if len(poll.Options) != 2 || poll.Options[0].Title != "Tabs :tab:" || poll.Options[0].VotesCount == nil || *poll.Options[0].VotesCount != 7 {
+		t.Errorf("got options %+v", poll.Options)
+	}
+	if len(poll.Emojis) != 1 || poll.Emojis[0].Shortcode != "tab" {
+		t.Errorf("got emojis %+v", poll.Emojis)
+	}
+	if poll.VotersCount == nil || *poll.VotersCount != 6 || !poll.Multiple || len(poll.OwnVotes) != 1 {
+		t.Errorf("got %+v", poll)
+	}
+}
+
+func TestStatusMediaRoundTrip(t *testing.T) {
+	var status Status
+	roundTrip(t, "status_media.json", &status)
+
+	if len(status.MediaAttachments) != 2 {
+		t.Fatalf("got %v attachments, want 2", len(status.MediaAttachments))
+	}
+	image, video := status.MediaAttachments[0], status.MediaAttachments[1]
+	if image.Meta.Focus == nil || image.Meta.Focus.X != -0.27 || image.Description == "" {
+		t.Errorf("got image %+v", image)
+	}
+	if video.Meta.Original == nil || video.Meta.Original.Duration != 88.654 || video.RemoteURL == "" {
+		t.Errorf("got video %+v", video)
+	}
+}
+
+func TestNotificationRoundTrip(t *testing.T) {
+	var note Notification
+	roundTrip(t, "notification.json", &note)
+
+	if note.Type != "favourite" || note.Account.Acct != "carol" {
+		t.Errorf("got %+v", note)
+	}
+	if note.Status == nil || note.Status.Account.Acct != "alice" {
+		t.Errorf("got status %+v", note.Status)
+	}
+}
+
+func TestAccountRoundTrip(t *testing.T) {
+	var account Account
+	roundTrip(t, "account.json", &account)
+
+	if len(account.Fields) != 2 || account.Fields[0].VerifiedAt == nil || account.Fields[1].VerifiedAt != nil {
+		t.Errorf("got fields %+v", account.Fields)
+	}
+	if len(account.Emojis) != 1 || account.LastStatusAt != "2026-10-16" {
+		t.Errorf("got %+v", account)
+	}
+}
+
+func TestCredentialAccountRoundTrip(t *testing.T) {
+	var account CredentialAccount
+	roundTrip(t, "credential_account.json", &account)
+
+	if account.Acct != "alice" || account.Source.Privacy != "unlisted" || account.Source.FollowRequestsCount != 2 {
+		t.Errorf("got %+v", account)
+	}
+	if len(account.Source.Fields) != 1 || account.Source.Fields[0].Name != "Pronouns" {
+		t.Errorf("got source fields %+v", account.Source.Fields)
+	}
+}
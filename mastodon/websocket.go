@@ -0,0 +1,263 @@
+package mastodon
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GUID the server appends to our key to prove it speaks WebSocket.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Largest message we're willing to buffer.
+const maxMessageSize = 16 << 20
+
+// Returned when the server won't upgrade, so the caller can fall back.
+var errWebSocketRefused = errors.New("websocket upgrade refused")
+
+// Struct for a client side WebSocket connection.
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Function to open a WebSocket connection and complete the handshake.
+func dialWebSocket(ctx context.Context, wsURL string, header http.Header) (*websocketConn, error) {
+	parsed, err := url.Parse(wsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work out where to connect.
+	host := parsed.Host
+	useTLS := parsed.Scheme == "wss" || parsed.Scheme == "https"
+	if parsed.Port() == "" {
+		if useTLS {
+			host = net.JoinHostPort(parsed.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(parsed.Hostname(), "80")
+		}
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if useTLS {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: parsed.Hostname()})
+		err = tlsConn.HandshakeContext(ctx)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	// Build the upgrade request.
+	keyBytes := make([]byte, 16)
+	_, err = rand.Read(keyBytes)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        parsed,
+		Host:       parsed.Host,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header.Clone(),
+	}
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Sec-WebSocket-Key", key)
+	request.Header.Set("Sec-WebSocket-Version", "13")
+
+	// Stop blocking on the handshake if the context goes away.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	err = request.Write(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		response.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", errWebSocketRefused, response.Status)
+	}
+
+	// Check the server actually read our key.
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	if response.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(hash[:]) {
+		conn.Close()
+		return nil, fmt.Errorf("%w: bad Sec-WebSocket-Accept", errWebSocketRefused)
+	}
+
+	return &websocketConn{conn: conn, reader: reader}, nil
+}
+
+// Function to read the next text or binary message, answering pings along the way.
+func (w *websocketConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		final, opcode, payload, err := w.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			err = w.writeFrame(opPong, payload)
+			if err != nil {
+				return nil, err
+			}
+		case opPong:
+			// Nothing to do.
+		case opClose:
+			w.writeFrame(opClose, nil)
+			return nil, io.EOF
+		case opText, opBinary, opContinuation:
+			message = append(message, payload...)
+			if len(message) > maxMessageSize {
+				return nil, errors.New("websocket message too large")
+			}
+			if final {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown websocket opcode %v", opcode)
+		}
+	}
+}
+
+// Function to read a single frame.
+func (w *websocketConn) readFrame() (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(w.reader, header)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	final := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	// The length is either in the header or in the next 2 or 8 bytes.
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		_, err = io.ReadFull(w.reader, extended)
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		_, err = io.ReadFull(w.reader, extended)
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if err != nil {
+		return false, 0, nil, err
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, errors.New("websocket frame too large")
+	}
+
+	// Servers shouldn't mask, but unmask if one does.
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		_, err = io.ReadFull(w.reader, mask)
+		if err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(w.reader, payload)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	for i := range mask {
+		for j := i; j < len(payload); j += 4 {
+			payload[j] ^= mask[i]
+		}
+	}
+
+	return final, opcode, payload, nil
+}
+
+// Function to write a single masked frame, as clients must.
+func (w *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+	}
+
+	mask := make([]byte, 4)
+	_, err := rand.Read(mask)
+	if err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err = w.conn.Write(frame)
+	return err
+}
+
+// Function to close the connection.
+func (w *websocketConn) Close() error {
+	return w.conn.Close()
+}
+
+// Function to turn an http(s) URL into a ws(s) one.
+func websocketScheme(rawURL string) string {
+	if strings.HasPrefix(rawURL, "https://") {
+		return "wss://" + strings.TrimPrefix(rawURL, "https://")
+	}
+	if strings.HasPrefix(rawURL, "http://") {
+		return "ws://" + strings.TrimPrefix(rawURL, "http://")
+	}
+	return rawURL
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Function to check a focal point is two numbers between -1 and 1.
+func validFocus(focus string) bool {
+	parts := strings.Split(focus, ",")
+	if len(parts) != 2 {
+		return false
+	}
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || value < -1 || value > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Function to get alt text for an attachment. It's required, so keep asking until there is some.
+func getAltText() (string, error) {
+	for {
+		description, err := promptLine("Describe it for people who can't see or hear it (alt text, required).")
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(description) != "" {
+			return description, nil
+		}
+		fmt.Println("Alt text is required for every attachment!")
+	}
+}
+
+// Function to upload one attachment, asking for its alt text and focal point.
+func uploadAttachment(ctx context.Context, client *mastodon.Client, path string) (mastodon.MediaAttachment, error) {
+	// Expand ~ since the shell didn't get a chance to.
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return mastodon.MediaAttachment{}, err
+	}
+
+	description, err := getAltText()
+	if err != nil {
+		return mastodon.MediaAttachment{}, err
+	}
+
+	// The focal point only matters for images, but it's harmless otherwise.
+	var focus string
+	for {
+		focus, err = promptLine("Focal point as x,y from -1 to 1, or press enter for the centre.")
+		if err != nil {
+			return mastodon.MediaAttachment{}, err
+		}
+		if focus == "" || validFocus(focus) {
+			break
+		}
+		fmt.Printf("%v is not a valid focal point!\n", focus)
+	}
+
+	fmt.Printf("Uploading %v...\n", filepath.Base(path))
+	media, err := client.UploadMedia(ctx, mastodon.MediaParams{
+		Path:        path,
+		Description: description,
+		Focus:       focus,
+	})
+	if err != nil {
+		return media, err
+	}
+
+	// Video and audio take a while to process.
+	if media.URL == "" {
+		fmt.Println("Waiting for the instance to process it...")
+		media, err = client.WaitForMedia(ctx, media)
+		if err != nil {
+			return media, err
+		}
+	}
+	fmt.Printf("Attached %v: %v\n", media.Type, media.URL)
+	return media, nil
+}
+
+// Function to offer to attach media or a poll to a draft, returning the IDs of any media uploaded or the poll.
+func attachMedia(ctx context.Context, session Session) ([]string, *mastodon.PollParams) {
+	var mediaIDs []string
+	for len(mediaIDs) < session.Limits.MaxMedia {
+		line, err := promptLine("Enter attach <path> to add media, poll to add a poll, or press enter to post.")
+		if err != nil {
+			fmt.Println(err)
+			return mediaIDs, nil
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return mediaIDs, nil
+		}
+
+		// A toot can have media or a poll, but not both.
+		if line == "poll" {
+			if len(mediaIDs) > 0 {
+				fmt.Println("A toot with media can't have a poll too!")
+				continue
+			}
+			poll, err := composePoll(session.Limits)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			return nil, poll
+		}
+
+		// Paths can have spaces in them, so take everything after the command.
+		if !strings.HasPrefix(line, "attach ") {
+			fmt.Println("Type attach followed by the path to the file, or poll.")
+			continue
+		}
+		media, err := uploadAttachment(ctx, session.Client, strings.TrimSpace(strings.TrimPrefix(line, "attach ")))
+		if err != nil {
+			printError(err)
+			continue
+		}
+		mediaIDs = append(mediaIDs, media.ID)
+	}
+
+	fmt.Printf("That's the most attachments your instance allows (%v).\n", session.Limits.MaxMedia)
+	return mediaIDs, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"os"
+	"text/template"
+)
+
+// Names of the output formats for read commands.
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatNDJSON   = "ndjson"
+	formatTemplate = "template"
+)
+
+// Struct for how read commands write out what they fetch.
+type outputFormat struct {
+	Name string
+	// Run once per item for the template format.
+	Template *template.Template
+}
+
+// Function to set up an output format by name. The template format needs the template text.
+func parseOutputFormat(name string, templateText string) (outputFormat, error) {
+	switch name {
+	case "", formatText:
+		return outputFormat{Name: formatText}, nil
+	case formatJSON, formatNDJSON:
+		return outputFormat{Name: name}, nil
+	case formatTemplate:
+		if templateText == "" {
+			return outputFormat{}, fmt.Errorf("the template format needs a template, e.g. {{.Account.Acct}}: {{.Content}}")
+		}
+		tmpl, err := template.New("output").Parse(templateText)
+		if err != nil {
+			return outputFormat{}, err
+		}
+		return outputFormat{Name: name, Template: tmpl}, nil
+	default:
+		return outputFormat{}, fmt.Errorf("%v is not an output format, use text, json, ndjson or template", name)
+	}
+}
+
+// Function to pick the output format from the flags, where a template on its own implies the template format.
+func chooseOutputFormat(name string, templateText string, fallback outputFormat) (outputFormat, error) {
+	if name == "" && templateText == "" {
+		return fallback, nil
+	}
+	if name == "" {
+		name = formatTemplate
+	}
+	return parseOutputFormat(name, templateText)
+}
+
+// Function to get the format to use for a stream, where items arrive one at a time.
+func (f outputFormat) streaming() outputFormat {
+	if f.Name == formatJSON {
+		return outputFormat{Name: formatNDJSON}
+	}
+	return f
+}
+
+// Function to write items in one of the machine-readable formats.
+func (f outputFormat) write(items []interface{}) error {
+	switch f.Name {
+	case formatJSON:
+		// Always an array, even when it's empty.
+		if items == nil {
+			items = []interface{}{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case formatNDJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		for _, item := range items {
+			err := encoder.Encode(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTemplate:
+		for _, item := range items {
+			err := f.Template.Execute(os.Stdout, item)
+			if err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		return nil
+	default:
+		return fmt.Errorf("%v is not a machine-readable format", f.Name)
+	}
+}
+
+// Function to turn a list of anything into items for write.
+func toItems[T any](list []T) []interface{} {
+	items := make([]interface{}, len(list))
+	for i := range list {
+		items[i] = list[i]
+	}
+	return items
+}
+
+// Function to print toots in the chosen format.
+func (f outputFormat) printToots(toots []mastodon.Status) {
+	if f.Name == formatText {
+		printToots(toots)
+		return
+	}
+	f.report(f.write(toItems(toots)))
+}
+
+// Struct for a notification as written out, with the ID of its toot at the top like a toot's.
+type notificationItem struct {
+	mastodon.Notification
+	ClientID int `json:"client_id,omitempty"`
+}
+
+// Function to turn notifications into items for write.
+func notificationItems(notes []mastodon.Notification) []interface{} {
+	items := make([]interface{}, len(notes))
+	for i, note := range notes {
+		item := notificationItem{Notification: note}
+		if note.Status != nil {
+			item.ClientID = note.Status.ClientID
+		}
+		items[i] = item
+	}
+	return items
+}
+
+// Function to print notifications in the chosen format.
+func (f outputFormat) printNotifications(notes []mastodon.Notification) {
+	if f.Name == formatText {
+		printNotifications(notes)
+		return
+	}
+	f.report(f.write(notificationItems(notes)))
+}
+
+// Function to report an error writing the output.
+func (f outputFormat) report(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write %v output: %v\n", f.Name, err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/JFFail/GoToot/mastodon"
+	"testing"
+)
+
+func TestNotificationItems(t *testing.T) {
+	notes := []mastodon.Notification{
+		{ID: "1", Type: "favourite", Account: mastodon.Account{Acct: "carol"}, Status: &mastodon.Status{ID: "9", ClientID: 3}},
+		{ID: "2", Type: "follow", Account: mastodon.Account{Acct: "dave"}},
+	}
+	items := notificationItems(notes)
+
+	// The JSON has the toot's ID at the top, and none for notifications without a toot.
+	var decoded []map[string]interface{}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.Unmarshal(raw, &decoded)
+	if len(decoded) != 2 || decoded[0]["client_id"] != 3.0 || decoded[0]["type"] != "favourite" {
+		t.Errorf("got %s", raw)
+	}
+	if _, found := decoded[1]["client_id"]; found {
+		t.Errorf("follow has a client_id in %s", raw)
+	}
+
+	// Templates can use the same fields as for toots.
+	format, err := parseOutputFormat(formatTemplate, "{{.ClientID}} {{.Account.Acct}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = format.Template.Execute(&out, items[0])
+	if err != nil || out.String() != "3 carol" {
+		t.Errorf("got %q, %v", out.String(), err)
+	}
+}
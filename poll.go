@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Function to format a poll for printing under its toot.
+func formatPoll(poll *mastodon.Poll) string {
+	var output strings.Builder
+
+	// Say what kind of poll it is and when it ends.
+	kind := "Poll"
+	if poll.Multiple {
+		kind = "Poll (choose any)"
+	}
+	switch {
+	case poll.Expired:
+		fmt.Fprintf(&output, "%v, closed", kind)
+	case poll.ExpiresAt != nil:
+		fmt.Fprintf(&output, "%v, ends at %v", kind, prettyTime(*poll.ExpiresAt))
+	default:
+		fmt.Fprintf(&output, "%v, open", kind)
+	}
+	if poll.Voted {
+		fmt.Fprintf(&output, ", you voted")
+	}
+	fmt.Fprintf(&output, ":\n")
+
+	// Multiple choice polls are shared out by voters rather than votes.
+	total := poll.VotesCount
+	if poll.Multiple && poll.VotersCount != nil {
+		total = *poll.VotersCount
+	}
+	ownVotes := make(map[int]bool)
+	for _, choice := range poll.OwnVotes {
+		ownVotes[choice] = true
+	}
+	for i, option := range poll.Options {
+		marker := " "
+		if ownVotes[i] {
+			marker = "*"
+		}
+		if option.VotesCount == nil {
+			fmt.Fprintf(&output, " %v %v. %v\n", marker, i+1, option.Title)
+			continue
+		}
+		percent := 0
+		if total > 0 {
+			percent = *option.VotesCount * 100 / total
+		}
+		fmt.Fprintf(&output, " %v %v. %v: %v votes (%v%%)\n", marker, i+1, option.Title, *option.VotesCount, percent)
+	}
+
+	// Totals can be hidden until the poll ends.
+	if len(poll.Options) > 0 && poll.Options[0].VotesCount == nil {
+		fmt.Fprintf(&output, "Totals are hidden until it ends.\n")
+	} else if poll.VotersCount != nil {
+		fmt.Fprintf(&output, "%v votes from %v people\n", poll.VotesCount, *poll.VotersCount)
+	} else {
+		fmt.Fprintf(&output, "%v votes\n", poll.VotesCount)
+	}
+	return output.String()
+}
+
+// Function to turn choices like "1,3" or "1 3" into option indexes.
+func parseVoteChoices(args []string, poll *mastodon.Poll) ([]int, error) {
+	var choices []int
+	seen := make(map[int]bool)
+	for _, arg := range args {
+		for _, field := range strings.Split(arg, ",") {
+			if field == "" {
+				continue
+			}
+			number, err := strconv.Atoi(field)
+			if err != nil || number < 1 || number > len(poll.Options) {
+				return nil, fmt.Errorf("%v is not an option, choose from 1 to %v", field, len(poll.Options))
+			}
+			if !seen[number] {
+				seen[number] = true
+				choices = append(choices, number-1)
+			}
+		}
+	}
+
+	// Check the number of choices fits the poll.
+	if len(choices) == 0 {
+		return nil, fmt.Errorf("choose at least one option")
+	}
+	if len(choices) > 1 && !poll.Multiple {
+		return nil, fmt.Errorf("that poll only allows one choice")
+	}
+	return choices, nil
+}
+
+// Function to vote in a toot's poll and update it in place.
+func voteInPoll(ctx context.Context, client *mastodon.Client, toot *mastodon.Status, args []string) {
+	if toot.Poll == nil {
+		fmt.Println("That toot doesn't have a poll!")
+		return
+	}
+	if toot.Poll.Expired {
+		fmt.Println("That poll has closed.")
+		return
+	}
+	if toot.Poll.Voted {
+		fmt.Println("You've already voted in that poll.")
+		return
+	}
+
+	// Prompt for the choices if they weren't given.
+	if len(args) == 0 {
+		fmt.Printf("\n%v", formatPoll(toot.Poll))
+		line, err := promptLine("Enter the numbers of your choices.")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		args = strings.Fields(line)
+	}
+	choices, err := parseVoteChoices(args, toot.Poll)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	poll, err := client.Vote(ctx, toot.Poll.ID, choices)
+	if err != nil {
+		printError(err)
+		return
+	}
+	toot.Poll = &poll
+	fmt.Printf("Voted!\n%v\n", formatPoll(toot.Poll))
+}
+
+// Function to parse a duration like 30m, 6h or 3d, allowing days as well as what time.ParseDuration takes.
+func parseDuration(text string) (time.Duration, error) {
+	if strings.HasSuffix(text, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(text, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("%v is not a valid duration", text)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("%v is not a valid duration", text)
+	}
+	return duration, nil
+}
+
+// Function to ask for the options and settings of a new poll.
+func composePoll(limits composeLimits) (*mastodon.PollParams, error) {
+	poll := &mastodon.PollParams{}
+
+	// Get the options, one at a time.
+	fmt.Printf("\nEnter up to %v options, one per line. Press enter on its own when done.\n", limits.MaxPollOptions)
+	for len(poll.Options) < limits.MaxPollOptions {
+		fmt.Printf("%v> ", len(poll.Options)+1)
+		line, err := stdin.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		option := strings.TrimSpace(line)
+		if option == "" {
+			if len(poll.Options) >= 2 {
+				break
+			}
+			fmt.Println("A poll needs at least two options!")
+			continue
+		}
+		if length := countGraphemes(option); length > limits.PollOptionCharacters {
+			fmt.Printf("That option is %v characters and the limit is %v! Try again...\n", length, limits.PollOptionCharacters)
+			continue
+		}
+		poll.Options = append(poll.Options, option)
+	}
+
+	// Then how long it runs for.
+	for {
+		text, err := promptLine("How long should it run? e.g. 30m, 6h or 3d. Press enter for 1d.")
+		if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			text = "1d"
+		}
+		duration, err := parseDuration(strings.TrimSpace(text))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if duration < limits.MinPollDuration || duration > limits.MaxPollDuration {
+			fmt.Printf("Polls can run from %v to %v!\n", limits.MinPollDuration, limits.MaxPollDuration)
+			continue
+		}
+		poll.ExpiresIn = int(duration / time.Second)
+		break
+	}
+
+	poll.Multiple = confirm("Allow choosing more than one option?")
+	poll.HideTotals = confirm("Hide the totals until it ends?")
+	return poll, nil
+}
+
+// Function to rebuild the settings of an existing poll so an edit keeps it.
+// A poll that has ended, or ends sooner than the instance allows a new one to run, can't be sent again.
+func existingPoll(poll *mastodon.Poll, limits composeLimits) (*mastodon.PollParams, error) {
+	if poll.Expired {
+		return nil, errors.New("its poll has ended")
+	}
+	if poll.ExpiresAt == nil {
+		return nil, errors.New("its poll has no end time to keep")
+	}
+	remaining := time.Until(*poll.ExpiresAt)
+	if remaining < limits.MinPollDuration {
+		return nil, fmt.Errorf("its poll ends in %v, sooner than the shortest poll the instance allows (%v)", remaining.Round(time.Second), limits.MinPollDuration)
+	}
+
+	params := &mastodon.PollParams{Multiple: poll.Multiple}
+	for _, option := range poll.Options {
+		params.Options = append(params.Options, option.Title)
+		if option.VotesCount == nil {
+			params.HideTotals = true
+		}
+	}
+
+	// Keep the same end time, as near as the API allows.
+	params.ExpiresIn = int(remaining / time.Second)
+	if time.Duration(params.ExpiresIn)*time.Second > limits.MaxPollDuration {
+		params.ExpiresIn = int(limits.MaxPollDuration / time.Second)
+	}
+	return params, nil
+}
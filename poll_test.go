@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/JFFail/GoToot/mastodon"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Function to build a poll ending after the given time.
+func pollEndingIn(remaining time.Duration) *mastodon.Poll {
+	expiresAt := time.Now().Add(remaining)
+	yes, no := 3, 1
+	return &mastodon.Poll{
+		ExpiresAt: &expiresAt,
+		Multiple:  true,
+		Options:   []mastodon.PollOption{{Title: "yes", VotesCount: &yes}, {Title: "no", VotesCount: &no}},
+	}
+}
+
+func TestExistingPoll(t *testing.T) {
+	limits := composeLimits{MinPollDuration: 5 * time.Minute, MaxPollDuration: 7 * 24 * time.Hour}
+
+	params, err := existingPoll(pollEndingIn(2*time.Hour), limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(params.Options) != 2 || !params.Multiple || params.HideTotals {
+		t.Errorf("got %+v", params)
+	}
+	if params.ExpiresIn < 7190 || params.ExpiresIn > 7200 {
+		t.Errorf("expires in %v seconds, want about 7200", params.ExpiresIn)
+	}
+}
+
+func TestExistingPollEnded(t *testing.T) {
+	limits := composeLimits{MinPollDuration: 5 * time.Minute, MaxPollDuration: 7 * 24 * time.Hour}
+
+	expired := pollEndingIn(-time.Hour)
+	expired.Expired = true
+	tests := []struct {
+		name string
+		poll *mastodon.Poll
+		want string
+	}{
+		{"expired", expired, "has ended"},
+		{"past its end", pollEndingIn(-time.Minute), "ends in"},
+		{"under the minimum", pollEndingIn(2 * time.Minute), "sooner than the shortest poll"},
+		{"no end", &mastodon.Poll{}, "no end time"},
+	}
+	for _, test := range tests {
+		_, err := existingPoll(test.poll, limits)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%v: got %v, want an error containing %q", test.name, err, test.want)
+		}
+	}
+}
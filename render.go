@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"jaytaylorcom/html2text"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// Kinds of item that can each have their own template.
+var templateKinds = []string{"status", "boost", "mention", "follow", "favourite", "poll", "notification"}
+
+// Built-in templates, matching how toots and notifications have always been printed.
+const builtinTemplates = `
+{{- define "toot" -}}
+> {{style "author" .Toot.Account.Acct}} from |{{app .Toot}}| to |{{.Toot.Visibility}}| at {{localtime .Toot.CreatedAt}}{{with .Toot.EditedAt}} (edited at {{localtime .}}){{end}}
+{{if .Toot.Sensitive}}{{style "cw" (printf ">> CW: %v" .Toot.SpoilerText)}}
+{{end}}
+{{html2text .Toot.Content | wrap .Width | highlight}}
+{{if .Toot.Reblogged}}
+{{end}}{{range media .Toot}}{{.Type}}: {{.URL}}
+{{end}}{{with .Toot.Poll}}{{poll .}}{{end}}{{template "footer" .}}
+{{separator .Width}}{{end}}
+
+{{- define "footer"}}{{style "footer" (printf "~=: ID: %v\tFavs: %v\tBoosts: %v :=~" .Toot.ClientID .Toot.FavouritesCount .Toot.ReblogsCount)}}{{end}}
+
+{{- define "note-status"}}{{if and .Toot .Toot.Content}}
+{{html2text .Toot.Content | wrap .Width | highlight}}
+{{with .Toot.Poll}}{{poll .}}{{end}}{{template "footer" .}}
+{{separator .Width}}
+{{end}}{{end}}
+
+{{- define "status"}}{{template "toot" .}}{{end}}
+
+{{- define "boost"}}{{if .Notification}}> Boost by {{style "author" .Notification.Account.Acct}}
+{{template "note-status" .}}{{else}}> {{range $i, $account := .BoostedBy}}{{if $i}}, {{end}}{{style "author" $account.Acct}}{{end}} boosted {{style "author" .Toot.Account.Acct}}
+{{template "toot" .}}{{end}}{{end}}
+
+{{- define "mention"}}> Mention by {{style "author" .Notification.Account.Acct}} from |{{app .Toot}}| to |{{.Toot.Visibility}}| at {{localtime .Toot.CreatedAt}}
+{{template "note-status" .}}{{end}}
+
+{{- define "favourite"}}> Favorite by {{style "author" .Notification.Account.Acct}}
+{{template "note-status" .}}{{end}}
+
+{{- define "poll"}}> Poll by {{style "author" .Toot.Account.Acct}} has ended
+{{template "note-status" .}}{{end}}
+
+{{- define "follow"}}> Followed by {{style "author" .Notification.Account.Acct}}
+>> Has posted {{.Notification.Account.StatusesCount}} statuses, the last on {{.Notification.Account.LastStatusAt}}
+{{html2text .Notification.Account.Note | wrap .Width | highlight}}
+{{style "footer" (printf "~=: Following: %v\tFollowers: %v :=~" .Notification.Account.FollowingCount .Notification.Account.FollowersCount)}}
+{{separator .Width}}
+{{template "note-status" .}}{{end}}
+
+{{- define "notification"}}{{printf "%+v" .Notification}}
+Not sure what to do with a type of {{.Notification.Type}}
+{{template "note-status" .}}{{end}}
+`
+
+// Templates used by the text output format, with any overrides from the config.
+var renderTemplates = template.Must(loadTemplates(nil))
+
+// Struct for what a template is given for each item.
+type renderItem struct {
+	// Which template it's rendered with.
+	Kind string
+	// The toot, or the toot a notification is about.
+	Toot *mastodon.Status
+	// The notification, if it is one.
+	Notification *mastodon.Notification
+	// Everyone who boosted the toot, for boosts in a timeline.
+	BoostedBy []mastodon.Account
+	// Columns to wrap text to. Zero means don't wrap.
+	Width int
+}
+
+// ANSI codes for the colour helper.
+var colourCodes = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+	"black":     "30",
+	"red":       "31",
+	"green":     "32",
+	"yellow":    "33",
+	"blue":      "34",
+	"magenta":   "35",
+	"cyan":      "36",
+	"white":     "37",
+}
+
+// Function to colour text with ANSI codes, e.g. {{colour "red" .Toot.Account.Acct}}. Plain when colour is off.
+func colour(name string, text string) (string, error) {
+	return applyStyle(name, text)
+}
+
+// Function to describe how long ago or how far ahead a time is, e.g. 5m ago.
+func relativeTime(t time.Time, now time.Time) string {
+	ago := now.Sub(t)
+	suffix := " ago"
+	if ago < 0 {
+		ago = -ago
+		suffix = ""
+	}
+
+	var amount string
+	switch {
+	case ago < time.Minute:
+		return "just now"
+	case ago < time.Hour:
+		amount = fmt.Sprintf("%vm", int(ago/time.Minute))
+	case ago < 24*time.Hour:
+		amount = fmt.Sprintf("%vh", int(ago/time.Hour))
+	case ago < 30*24*time.Hour:
+		amount = fmt.Sprintf("%vd", int(ago/(24*time.Hour)))
+	default:
+		return t.Local().Format("2006-01-02")
+	}
+	if suffix == "" {
+		return "in " + amount
+	}
+	return amount + suffix
+}
+
+// Function to shorten text to at most length characters, marking where it was cut.
+func truncate(length int, text string) string {
+	if length < 1 || utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:length-1]) + "…"
+}
+
+// Helper functions available to every template.
+var templateFuncs = template.FuncMap{
+	"html2text": func(html string) (string, error) { return html2text.FromString(html) },
+	"truncate":  truncate,
+	"colour":    colour,
+	"color":     colour,
+	"localtime": prettyTime,
+	"style":     themeStyle,
+	"highlight": highlight,
+	"wrap":      wrapText,
+	"separator": separatorLine,
+	"reltime": func(t interface{}) (string, error) {
+		switch value := t.(type) {
+		case time.Time:
+			return relativeTime(value, time.Now()), nil
+		case *time.Time:
+			if value == nil {
+				return "", nil
+			}
+			return relativeTime(*value, time.Now()), nil
+		default:
+			return "", fmt.Errorf("reltime needs a time, not %T", t)
+		}
+	},
+	"app": func(toot *mastodon.Status) string {
+		if toot.Application != nil && toot.Application.Name != "" {
+			return toot.Application.Name
+		}
+		return "Web"
+	},
+	"media": func(toot *mastodon.Status) []mastodon.MediaAttachment {
+		// Last attachment first, as it's always been shown.
+		var media []mastodon.MediaAttachment
+		for i := len(toot.MediaAttachments) - 1; i >= 0; i-- {
+			media = append(media, toot.MediaAttachments[i])
+		}
+		return media
+	},
+	"poll": formatPoll,
+}
+
+// Function to parse the built-in templates and then any overrides, by kind.
+func loadTemplates(overrides map[string]string) (*template.Template, error) {
+	templates, err := template.New("builtin").Funcs(templateFuncs).Parse(builtinTemplates)
+	if err != nil {
+		return nil, err
+	}
+	for kind, text := range overrides {
+		known := false
+		for _, templateKind := range templateKinds {
+			known = known || kind == templateKind
+		}
+		if !known {
+			return nil, fmt.Errorf("no template kind %v, use one of %v", kind, strings.Join(templateKinds, ", "))
+		}
+		_, err = templates.New(kind).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// Function to work out which template a toot is rendered with.
+func tootKind(toot mastodon.Status) string {
+	if toot.Reblog != nil {
+		return "boost"
+	}
+	return "status"
+}
+
+// Function to work out which template a notification is rendered with.
+func notificationKind(note mastodon.Notification) string {
+	switch note.Type {
+	case "mention", "follow", "favourite", "poll":
+		return note.Type
+	case "reblog", "boost":
+		return "boost"
+	default:
+		return "notification"
+	}
+}
+
+// Function to render an item with the template for its kind.
+func render(item renderItem) (string, error) {
+	var output bytes.Buffer
+	err := renderTemplates.ExecuteTemplate(&output, item.Kind, item)
+	return output.String(), err
+}
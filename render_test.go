@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run go test -update to rewrite the golden files after changing a template.
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Narrow enough that every fixture has to wrap.
+const narrowWidth = 30
+
+// Function to set the terminal up for a test and put it back afterwards.
+func useTerminal(t *testing.T, colour bool, width int) {
+	oldTheme, oldColour, oldWidth, oldLocal := activeTheme, colourEnabled, terminalWidth, time.Local
+	t.Cleanup(func() {
+		activeTheme, colourEnabled, terminalWidth, time.Local = oldTheme, oldColour, oldWidth, oldLocal
+	})
+	activeTheme = builtinThemes["default"]
+	colourEnabled = colour
+	terminalWidth = width
+	time.Local = time.UTC
+}
+
+// Function to compare output with a golden file, or rewrite it with -update.
+func checkGolden(t *testing.T, path string, got string) {
+	t.Helper()
+	if *updateGolden {
+		err := os.WriteFile(path, []byte(got), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("output doesn't match %v\ngot:\n%v\nwant:\n%v", path, got, want)
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "render", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no render fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		raw, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var item renderItem
+		err = json.Unmarshal(raw, &item)
+		if err != nil {
+			t.Fatalf("%v: %v", fixture, err)
+		}
+		name := strings.TrimSuffix(fixture, ".json")
+
+		for _, width := range []int{0, narrowWidth} {
+			for _, colour := range []bool{false, true} {
+				variant := "plain"
+				if colour {
+					variant = "colour"
+				}
+				golden := fmt.Sprintf("%v.%v.w%v.golden", name, variant, width)
+
+				t.Run(filepath.Base(golden), func(t *testing.T) {
+					useTerminal(t, colour, width)
+					item.Width = width
+					output, err := render(item)
+					if err != nil {
+						t.Fatal(err)
+					}
+					checkGolden(t, golden, output)
+				})
+			}
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		width int
+		in    string
+		want  string
+	}{
+		{0, "left as it is however long it gets", "left as it is however long it gets"},
+		{10, "short", "short"},
+		{10, "keeps  its  spacing", "keeps its\nspacing"},
+		{10, "one two three four", "one two\nthree four"},
+		{10, "a https://example.com/long/path b", "a\nhttps://example.com/long/path\nb"},
+		{10, "first line\nsecond line is longer", "first line\nsecond\nline is\nlonger"},
+		{5, "héllo wörld", "héllo\nwörld"},
+	}
+	for _, test := range tests {
+		got := wrapText(test.width, test.in)
+		if got != test.want {
+			t.Errorf("wrapText(%v, %q) = %q, want %q", test.width, test.in, got, test.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	useTerminal(t, true, 0)
+	activeTheme = Theme{Mention: "red", Hashtag: "green", Link: "blue"}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"hi @bob", "hi \x1b[31m@bob\x1b[0m"},
+		{"hi @bob@example.social.", "hi \x1b[31m@bob@example.social\x1b[0m."},
+		{"mail alice@example.com", "mail alice@example.com"},
+		{"about #golang", "about \x1b[32m#golang\x1b[0m"},
+		{"issue a#1", "issue a#1"},
+		{"see https://example.com/post#section", "see \x1b[34mhttps://example.com/post#section\x1b[0m"},
+		{"path/#not-a-tag", "path/#not-a-tag"},
+	}
+	for _, test := range tests {
+		got, err := highlight(test.in)
+		if err != nil || got != test.want {
+			t.Errorf("highlight(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+
+	// Without colour nothing changes.
+	colourEnabled = false
+	got, err := highlight("hi @bob #golang")
+	if err != nil || got != "hi @bob #golang" {
+		t.Errorf("got %q, %v with colour off", got, err)
+	}
+}
+
+func TestSeparatorLine(t *testing.T) {
+	useTerminal(t, false, 0)
+
+	if got := separatorLine(0); got != "" {
+		t.Errorf("got %q at width 0", got)
+	}
+	if got := separatorLine(5); got != "─────\n" {
+		t.Errorf("got %q at width 5", got)
+	}
+
+	// Multi-character separators are repeated as far as they fit.
+	activeTheme.Separator = "-="
+	if got := separatorLine(5); got != "-=-=\n" {
+		t.Errorf("got %q for a two character separator", got)
+	}
+
+	// The footer style is used when colour is on.
+	colourEnabled = true
+	activeTheme.Separator = "-"
+	if got := separatorLine(3); got != "\x1b[2m---\x1b[0m\n" {
+		t.Errorf("got %q with colour", got)
+	}
+
+	activeTheme = builtinThemes["plain"]
+	if got := separatorLine(5); got != "" {
+		t.Errorf("got %q with no separator", got)
+	}
+}
+
+func TestTerminalOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLUMNS", "")
+
+	// Pipes and files get neither colour nor wrapping.
+	colour, width := terminalOutput(false, 120)
+	if colour || width != 0 {
+		t.Errorf("got %v, %v for a pipe", colour, width)
+	}
+
+	colour, width = terminalOutput(true, 120)
+	if !colour || width != 120 {
+		t.Errorf("got %v, %v for a terminal", colour, width)
+	}
+
+	// The size falls back to $COLUMNS and then a default.
+	t.Setenv("COLUMNS", "100")
+	if _, width = terminalOutput(true, 0); width != 100 {
+		t.Errorf("got width %v, want $COLUMNS", width)
+	}
+	t.Setenv("COLUMNS", "")
+	if _, width = terminalOutput(true, 0); width != defaultTerminalWidth {
+		t.Errorf("got width %v, want the default", width)
+	}
+
+	// NO_COLOR turns colour off but keeps the wrapping.
+	t.Setenv("NO_COLOR", "1")
+	colour, width = terminalOutput(true, 120)
+	if colour || width != 120 {
+		t.Errorf("got %v, %v with NO_COLOR", colour, width)
+	}
+}
+
+func TestSetupTerminalChecksTheme(t *testing.T) {
+	useTerminal(t, false, 0)
+
+	configInfo := Config{Theme: "mine", Themes: map[string]Theme{"mine": {Author: "bold cyan", Link: "blue, underline"}}}
+	err := setupTerminal(configInfo)
+	if err != nil || activeTheme.Author != "bold cyan" {
+		t.Errorf("got %v with theme %+v", err, activeTheme)
+	}
+
+	// A typo in any style stops it, naming the colour and where it is.
+	configInfo.Themes["mine"] = Theme{Author: "bold cyan", Footer: "dimm"}
+	err = setupTerminal(configInfo)
+	if err == nil || !strings.Contains(err.Error(), "dimm") || !strings.Contains(err.Error(), "footer") {
+		t.Errorf("got %v for a bad footer style", err)
+	}
+
+	configInfo.Theme = "missing"
+	if err = setupTerminal(configInfo); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("got %v for a missing theme", err)
+	}
+
+	// The built-in themes are all valid.
+	for name, theme := range builtinThemes {
+		if err := theme.check(); err != nil {
+			t.Errorf("built-in theme %v: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How far ahead the instance wants scheduled toots to be.
+const minScheduleAhead = 5 * time.Minute
+
+// Layouts accepted for an absolute --at time, read in the local timezone.
+var scheduleLayouts = []string{
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Function to parse when to post, either +2h style from now or an absolute local time.
+func parseScheduleTime(text string, now time.Time) (time.Time, error) {
+	var at time.Time
+	switch {
+	case strings.HasPrefix(text, "+"):
+		duration, err := parseDuration(strings.TrimPrefix(text, "+"))
+		if err != nil {
+			return at, err
+		}
+		at = now.Add(duration)
+	case len(text) == 5 && strings.Contains(text, ":"):
+		// Just a time means the next time it comes round.
+		clock, err := time.ParseInLocation("15:04", text, now.Location())
+		if err != nil {
+			return at, fmt.Errorf("%v is not a valid time", text)
+		}
+		at = time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+	default:
+		parsed, err := time.Parse(time.RFC3339, text)
+		for _, layout := range scheduleLayouts {
+			if err == nil {
+				break
+			}
+			parsed, err = time.ParseInLocation(layout, text, now.Location())
+		}
+		if err != nil {
+			return at, fmt.Errorf("%v is not a valid time, use e.g. +2h, 15:30 or 2006-01-02T15:04", text)
+		}
+		at = parsed
+	}
+
+	if at.Before(now.Add(minScheduleAhead)) {
+		return at, fmt.Errorf("scheduled toots must be at least %v in the future", minScheduleAhead)
+	}
+	return at, nil
+}
+
+// Function to schedule a draft rather than posting it now.
+func scheduleDraft(ctx context.Context, client *mastodon.Client, params mastodon.StatusParams, at time.Time) {
+	scheduled, err := client.ScheduleStatus(ctx, params, at)
+	if err != nil {
+		printError(err)
+		return
+	}
+	fmt.Printf("Successfully scheduled toot for %v\n\n", prettyTime(scheduled.ScheduledAt))
+}
+
+// Function to print the scheduled toots, numbered for the other scheduled commands.
+func printScheduled(scheduled []mastodon.ScheduledStatus, output outputFormat) {
+	if output.Name != formatText {
+		output.report(output.write(toItems(scheduled)))
+		return
+	}
+	if len(scheduled) == 0 {
+		fmt.Println("No toots are scheduled.")
+		return
+	}
+	for i, toot := range scheduled {
+		fmt.Printf("> [%v] at %v to |%v|\n", i+1, prettyTime(toot.ScheduledAt), toot.Params.Visibility)
+		if toot.Params.SpoilerText != "" {
+			fmt.Printf(">> CW: %v\n", toot.Params.SpoilerText)
+		}
+		fmt.Printf("\n%v\n", toot.Params.Text)
+		if len(toot.MediaAttachments) > 0 {
+			fmt.Printf("(%v attachments)\n", len(toot.MediaAttachments))
+		}
+		if toot.Params.Poll != nil {
+			fmt.Printf("(poll: %v)\n", strings.Join(toot.Params.Poll.Options, " / "))
+		}
+		fmt.Println()
+	}
+}
+
+// Function to pick a scheduled toot by the number it was listed with.
+func pickScheduled(scheduled []mastodon.ScheduledStatus, arg string) (mastodon.ScheduledStatus, error) {
+	number, err := strconv.Atoi(arg)
+	if err != nil || number < 1 || number > len(scheduled) {
+		return mastodon.ScheduledStatus{}, fmt.Errorf("%v is not a scheduled toot, run scheduled to list them", arg)
+	}
+
+	// Cancelled toots keep their place in the list but have no ID.
+	if scheduled[number-1].ID == "" {
+		return mastodon.ScheduledStatus{}, fmt.Errorf("scheduled toot %v has been cancelled, run scheduled to list them again", arg)
+	}
+	return scheduled[number-1], nil
+}
+
+// Function to run the scheduled commands, returning the latest list of scheduled toots.
+func runScheduled(ctx context.Context, client *mastodon.Client, args []string, listed []mastodon.ScheduledStatus, output outputFormat) ([]mastodon.ScheduledStatus, error) {
+	// Plain scheduled lists them.
+	if len(args) == 0 || strings.ToLower(args[0]) == "list" {
+		scheduled, err := client.ScheduledStatuses(ctx)
+		if err != nil {
+			return listed, err
+		}
+		printScheduled(scheduled, output)
+		return scheduled, nil
+	}
+
+	// The others need a list to pick from.
+	if listed == nil {
+		scheduled, err := client.ScheduledStatuses(ctx)
+		if err != nil {
+			return listed, err
+		}
+		listed = scheduled
+	}
+	switch strings.ToLower(args[0]) {
+	case "reschedule":
+		if len(args) < 3 {
+			return listed, errors.New("usage: scheduled reschedule <number> <time>")
+		}
+		toot, err := pickScheduled(listed, args[1])
+		if err != nil {
+			return listed, err
+		}
+		at, err := parseScheduleTime(args[2], time.Now())
+		if err != nil {
+			return listed, err
+		}
+		rescheduled, err := client.RescheduleStatus(ctx, toot.ID, at)
+		if err != nil {
+			return listed, err
+		}
+		fmt.Printf("Rescheduled toot %v for %v\n\n", args[1], prettyTime(rescheduled.ScheduledAt))
+
+		// Keep the numbering as it was listed.
+		for i := range listed {
+			if listed[i].ID == toot.ID {
+				listed[i] = rescheduled
+			}
+		}
+		return listed, nil
+	case "cancel":
+		if len(args) < 2 {
+			return listed, errors.New("usage: scheduled cancel <number>")
+		}
+		toot, err := pickScheduled(listed, args[1])
+		if err != nil {
+			return listed, err
+		}
+		if !confirm(fmt.Sprintf("Cancel the toot scheduled for %v?", prettyTime(toot.ScheduledAt))) {
+			fmt.Println("Not cancelled.")
+			return listed, nil
+		}
+		err = client.CancelScheduledStatus(ctx, toot.ID)
+		if err != nil {
+			return listed, err
+		}
+		fmt.Printf("Cancelled toot %v\n\n", args[1])
+
+		// Keep the numbering as it was listed, but don't let it be picked again.
+		for i := range listed {
+			if listed[i].ID == toot.ID {
+				listed[i].ID = ""
+			}
+		}
+		return listed, nil
+	default:
+		return listed, fmt.Errorf("unknown scheduled command %v, use list, reschedule or cancel", args[0])
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"github.com/JFFail/GoToot/mastodon"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunScheduledCancel(t *testing.T) {
+	deletes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/v1/scheduled_statuses/2" {
+			t.Errorf("unexpected %v %v", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		deletes++
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := mastodon.NewClient(server.URL, "tok")
+
+	// Answer yes to the confirmation.
+	oldStdin := stdin
+	stdin = bufio.NewReader(strings.NewReader("y\n"))
+	defer func() { stdin = oldStdin }()
+
+	listed := []mastodon.ScheduledStatus{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	listed, err := runScheduled(context.Background(), client, []string{"cancel", "2"}, listed, outputFormat{Name: formatText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 3 || listed[0].ID != "1" || listed[2].ID != "3" {
+		t.Errorf("numbering changed: %+v", listed)
+	}
+
+	// Picking it again says so rather than asking the instance.
+	for _, args := range [][]string{{"cancel", "2"}, {"reschedule", "2", "+2h"}} {
+		_, err = runScheduled(context.Background(), client, args, listed, outputFormat{Name: formatText})
+		if err == nil || !strings.Contains(err.Error(), "has been cancelled") {
+			t.Errorf("%q: got %v, want a cancelled error", args, err)
+		}
+	}
+	if deletes != 1 {
+		t.Errorf("cancelled %v times, want 1", deletes)
+	}
+}
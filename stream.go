@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// Function to work out which stream the user asked for.
+func parseStreamArgs(args []string) (mastodon.StreamParams, error) {
+	if len(args) == 0 {
+		return mastodon.StreamParams{Stream: mastodon.StreamUser}, nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "home":
+		return mastodon.StreamParams{Stream: mastodon.StreamUser}, nil
+	case "local":
+		return mastodon.StreamParams{Stream: mastodon.StreamLocal}, nil
+	case "public":
+		return mastodon.StreamParams{Stream: mastodon.StreamPublic}, nil
+	case "notifications", "notes", "note":
+		return mastodon.StreamParams{Stream: mastodon.StreamNotifications}, nil
+	case "hashtag":
+		if len(args) < 2 {
+			return mastodon.StreamParams{}, errors.New("usage: stream hashtag <tag>")
+		}
+		return mastodon.StreamParams{Stream: mastodon.StreamHashtag, Tag: strings.TrimPrefix(args[1], "#")}, nil
+	case "list":
+		if len(args) < 2 {
+			return mastodon.StreamParams{}, errors.New("usage: stream list <id>")
+		}
+		return mastodon.StreamParams{Stream: mastodon.StreamList, List: args[1]}, nil
+	default:
+		return mastodon.StreamParams{}, fmt.Errorf("unknown stream %v", args[0])
+	}
+}
+
+// Function to print a stream live until the user hits Ctrl-C, returning what was shown.
+func runStream(ctx context.Context, client *mastodon.Client, params mastodon.StreamParams, tootCounter int, output outputFormat) (*timelineState, int, error) {
+	// Ctrl-C stops the stream instead of the whole program.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	state := &timelineState{Name: "stream"}
+	output = output.streaming()
+	params.OnDisconnect = func(err error, wait time.Duration) {
+		fmt.Printf("Stream disconnected (%v), reconnecting in %v...\n", err, wait.Round(time.Second))
+	}
+	fmt.Printf("Streaming %v, press Ctrl-C to stop.\n\n", params.Stream)
+
+	err := client.Stream(ctx, params, func(event mastodon.StreamEvent) {
+		switch event.Event {
+		case "update", "status.update":
+			toots, counter := assignIndexToots([]mastodon.Status{*event.Status}, tootCounter)
+			tootCounter = counter
+			state.Toots = append(toots, state.Toots...)
+			if event.Event == "status.update" && output.Name == formatText {
+				fmt.Println("> Edited:")
+			}
+			output.printToots(toots)
+		case "notification":
+			notes, counter := assignIndexNotes([]mastodon.Notification{*event.Notification}, tootCounter)
+			tootCounter = counter
+			state.Notes = append(notes, state.Notes...)
+			output.printNotifications(notes)
+		case "delete":
+			if output.Name != formatText {
+				return
+			}
+			fmt.Printf("> Status %v was deleted\n\n", event.DeletedID)
+		}
+	})
+
+	// Stopping with Ctrl-C isn't an error.
+	if errors.Is(err, context.Canceled) {
+		err = nil
+	}
+	fmt.Println()
+	return state, tootCounter, err
+}
@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import (
+	"os"
+)
+
+// Function to ask the terminal how many columns it has. Other systems fall back to $COLUMNS.
+func terminalColumns(file *os.File) int {
+	return 0
+}
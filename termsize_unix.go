@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Struct for the terminal size the kernel reports.
+type winsize struct {
+	Rows    uint16
+	Columns uint16
+	XPixels uint16
+	YPixels uint16
+}
+
+// Function to ask the terminal how many columns it has. Returns 0 if it can't tell.
+func terminalColumns(file *os.File) int {
+	var size winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0
+	}
+	return int(size.Columns)
+}
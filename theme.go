@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Width used when stdout is a terminal but its size can't be found.
+const defaultTerminalWidth = 80
+
+// Narrowest text is wrapped to, however deep a thread goes.
+const minWrapWidth = 20
+
+// Struct for the styles used to highlight parts of a toot. Styles are names from colourCodes, e.g. "bold cyan".
+type Theme struct {
+	Author  string `json:"author"`
+	CW      string `json:"cw"`
+	Mention string `json:"mention"`
+	Hashtag string `json:"hashtag"`
+	Link    string `json:"link"`
+	Footer  string `json:"footer"`
+	// Drawn across the terminal after each toot. Empty leaves just the blank line.
+	Separator string `json:"separator"`
+}
+
+// Themes that are always available. The config can add more or replace these.
+var builtinThemes = map[string]Theme{
+	"default": {
+		Author:    "bold cyan",
+		CW:        "bold yellow",
+		Mention:   "magenta",
+		Hashtag:   "green",
+		Link:      "blue underline",
+		Footer:    "dim",
+		Separator: "─",
+	},
+	"plain": {},
+}
+
+// How output to the terminal looks, set up at startup.
+var (
+	activeTheme   = builtinThemes["default"]
+	colourEnabled = false
+	// Zero means don't wrap.
+	terminalWidth = 0
+)
+
+// Mentions, hashtags and links, matched together so a link's #fragment isn't taken for a hashtag.
+var highlightPattern = regexp.MustCompile(`https?://[^\s<>"]+|(?i)@\w+(?:@[a-z0-9.\-]+[a-z0-9])?|#[\p{L}\p{N}_]+`)
+
+// Function to check whether stdout is a terminal rather than a pipe or file.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Function to pick the theme and work out colour and width for stdout.
+func setupTerminal(configInfo Config) error {
+	name := configInfo.Theme
+	if name == "" {
+		name = "default"
+	}
+	theme, found := configInfo.Themes[name]
+	if !found {
+		theme, found = builtinThemes[name]
+	}
+	if !found {
+		return fmt.Errorf("no theme named %v", name)
+	}
+	err := theme.check()
+	if err != nil {
+		return fmt.Errorf("theme %v: %v", name, err)
+	}
+	activeTheme = theme
+
+	colourEnabled, terminalWidth = terminalOutput(stdoutIsTerminal(), terminalColumns(os.Stdout))
+	return nil
+}
+
+// Function to work out whether to colour and how wide to wrap, given whether stdout is a terminal and how many columns it reports.
+func terminalOutput(isTerminal bool, columns int) (bool, int) {
+	// Pipes and files get plain, unwrapped text.
+	if !isTerminal {
+		return false, 0
+	}
+	width := columns
+	if width <= 0 {
+		width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if width <= 0 {
+		width = defaultTerminalWidth
+	}
+	return os.Getenv("NO_COLOR") == "", width
+}
+
+// Function to check every style in a theme is made of known colours, so a typo shows at startup.
+func (t Theme) check() error {
+	styles := []struct {
+		part  string
+		style string
+	}{
+		{"author", t.Author},
+		{"cw", t.CW},
+		{"mention", t.Mention},
+		{"hashtag", t.Hashtag},
+		{"link", t.Link},
+		{"footer", t.Footer},
+	}
+	for _, style := range styles {
+		_, err := styleCodes(style.style)
+		if err != nil {
+			return fmt.Errorf("%v in the %v style", err, style.part)
+		}
+	}
+	return nil
+}
+
+// Function to look up the ANSI codes for a style like "bold cyan".
+func styleCodes(style string) ([]string, error) {
+	var codes []string
+	for _, name := range strings.Fields(strings.ReplaceAll(style, ",", " ")) {
+		code, found := colourCodes[strings.ToLower(name)]
+		if !found {
+			return nil, fmt.Errorf("unknown colour %v", name)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// Function to wrap text in the ANSI codes for a style like "bold cyan".
+func applyStyle(style string, text string) (string, error) {
+	if !colourEnabled || style == "" || text == "" {
+		return text, nil
+	}
+	codes, err := styleCodes(style)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\x1b[%vm%v\x1b[0m", strings.Join(codes, ";"), text), nil
+}
+
+// Function to style text with a part of the theme, e.g. {{style "author" .Toot.Account.Acct}}.
+func themeStyle(part string, text string) (string, error) {
+	switch part {
+	case "author":
+		return applyStyle(activeTheme.Author, text)
+	case "cw":
+		return applyStyle(activeTheme.CW, text)
+	case "mention":
+		return applyStyle(activeTheme.Mention, text)
+	case "hashtag":
+		return applyStyle(activeTheme.Hashtag, text)
+	case "link":
+		return applyStyle(activeTheme.Link, text)
+	case "footer":
+		return applyStyle(activeTheme.Footer, text)
+	default:
+		return "", fmt.Errorf("no theme style %v, use author, cw, mention, hashtag, link or footer", part)
+	}
+}
+
+// Function to highlight the mentions, hashtags and links in plain toot text.
+func highlight(text string) (string, error) {
+	var highlighted strings.Builder
+	last := 0
+	for _, match := range highlightPattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		found := text[start:end]
+
+		// Work out what it is, skipping things like the @ in an email address.
+		part := "link"
+		switch found[0] {
+		case '@':
+			part = "mention"
+		case '#':
+			part = "hashtag"
+		}
+		if part != "link" && start > 0 && isWordByte(text[start-1]) {
+			continue
+		}
+
+		styled, err := themeStyle(part, found)
+		if err != nil {
+			return text, err
+		}
+		highlighted.WriteString(text[last:start])
+		highlighted.WriteString(styled)
+		last = end
+	}
+	highlighted.WriteString(text[last:])
+	return highlighted.String(), nil
+}
+
+// Function to check if a byte can be part of a word, so a mention or hashtag can't start after it.
+func isWordByte(b byte) bool {
+	return b == '_' || b == '/' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+// Function to word-wrap text to a width, keeping its own line breaks. Words longer than the width are left whole.
+func wrapText(width int, text string) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		var wrapped strings.Builder
+		length := 0
+		for _, word := range strings.Fields(line) {
+			wordLength := countGraphemes(word)
+			if length > 0 && length+1+wordLength > width {
+				wrapped.WriteString("\n")
+				length = 0
+			} else if length > 0 {
+				wrapped.WriteString(" ")
+				length++
+			}
+			wrapped.WriteString(word)
+			length += wordLength
+		}
+		// Leave lines that fit alone so their spacing survives.
+		if countGraphemes(line) > width {
+			lines[i] = wrapped.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Function to draw the theme's separator across the width, with its own newline.
+func separatorLine(width int) string {
+	if width <= 0 || countGraphemes(activeTheme.Separator) == 0 {
+		return ""
+	}
+	line := strings.Repeat(activeTheme.Separator, width/countGraphemes(activeTheme.Separator))
+	styled, err := applyStyle(activeTheme.Footer, line)
+	if err != nil {
+		return line + "\n"
+	}
+	return styled + "\n"
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"strings"
+)
+
+// Indentation added for each level of replies.
+const threadIndent = "    "
+
+// Function to fetch and print the thread around a toot, returning it and the new toot counter.
+func showThread(ctx context.Context, client *mastodon.Client, toot mastodon.Status, tootCounter int, output outputFormat) (*timelineState, int, error) {
+	thread, err := client.StatusContext(ctx, toot.ID)
+	if err != nil {
+		return nil, tootCounter, err
+	}
+
+	// Put the whole thread in reading order.
+	var all []mastodon.Status
+	all = append(all, thread.Ancestors...)
+	all = append(all, toot)
+	all = append(all, thread.Descendants...)
+
+	// Work out how deep each toot is from who it replies to. Parents always come before their replies.
+	depths := make(map[string]int)
+	for i := range all {
+		depth := 0
+		if parent, found := depths[all[i].InReplyToID]; found {
+			depth = parent + 1
+		}
+		depths[all[i].ID] = depth
+
+		// Give each one an ID in the order they're shown.
+		tootCounter++
+		all[i].ClientID = tootCounter
+	}
+
+	// Print the tree, marking the toot that was asked about. Other formats get the toots in reading order.
+	if output.Name != formatText {
+		output.printToots(all)
+	} else {
+		for _, entry := range all {
+			indent := strings.Repeat(threadIndent, depths[entry.ID])
+			if entry.ID == toot.ID {
+				fmt.Printf("%v>>> Selected toot:\n", indent)
+			}
+			printToot(entry, nil, indent)
+		}
+	}
+
+	// Reverse it so the thread is stored newest first like a timeline.
+	for i, j := 0, len(all)-1; i < j; i, j = i+1, j-1 {
+		all[i], all[j] = all[j], all[i]
+	}
+	return &timelineState{Name: "thread", Toots: all}, tootCounter, nil
+}
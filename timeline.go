@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"strings"
+)
+
+// Number of toots fetched per page unless the config says otherwise.
+const defaultPageSize = 20
+
+// Directions to page through a timeline.
+const (
+	pageFirst = iota
+	pageOlder
+	pageNewer
+)
+
+// Struct for everything fetched so far from one timeline.
+type timelineState struct {
+	Name string
+	// How many to fetch per page, kept so more and newer match the first page.
+	Limit  int
+	Cursor mastodon.Page
+	Toots  []mastodon.Status
+	Notes  []mastodon.Notification
+}
+
+// Function to get the canonical name of a timeline.
+func timelineName(name string) string {
+	name = strings.ToLower(name)
+	if name == "note" {
+		return "notes"
+	}
+	return name
+}
+
+// Function to fetch a page of a timeline by name.
+func fetchPage(ctx context.Context, client *mastodon.Client, name string, page mastodon.PageParams) ([]mastodon.Status, []mastodon.Notification, mastodon.Page, error) {
+	switch name {
+	case "home":
+		toots, cursor, err := client.HomeTimeline(ctx, page)
+		return toots, nil, cursor, err
+	case "local":
+		toots, cursor, err := client.PublicTimeline(ctx, true, page)
+		return toots, nil, cursor, err
+	case "notes":
+		notes, cursor, err := client.Notifications(ctx, page)
+		return nil, notes, cursor, err
+	default:
+		return nil, nil, mastodon.Page{}, fmt.Errorf("can't page through the %v", name)
+	}
+}
+
+// Function to fetch and print a page of a timeline, returning the new toot counter.
+func showPage(ctx context.Context, client *mastodon.Client, state *timelineState, direction int, tootCounter int, output outputFormat) (int, error) {
+	// Work out where the page starts.
+	page := mastodon.PageParams{Limit: state.Limit}
+	switch direction {
+	case pageOlder:
+		if state.Cursor.MaxID == "" {
+			fmt.Println("Nothing older to show.")
+			return tootCounter, nil
+		}
+		page.MaxID = state.Cursor.MaxID
+	case pageNewer:
+		page.MinID = state.Cursor.MinID
+		if page.MinID == "" {
+			page.SinceID = state.Cursor.SinceID
+		}
+	}
+
+	toots, notes, cursor, err := fetchPage(ctx, client, state.Name, page)
+	if err != nil {
+		return tootCounter, err
+	}
+	if len(toots) == 0 && len(notes) == 0 {
+		fmt.Println("Nothing new to show.")
+		return tootCounter, nil
+	}
+
+	// Assign each toot an index for this app, carrying on from the last page.
+	toots, tootCounter = assignIndexToots(toots, tootCounter)
+	notes, tootCounter = assignIndexNotes(notes, tootCounter)
+
+	// Keep the cursor for whichever end of the timeline moved.
+	switch direction {
+	case pageFirst:
+		state.Cursor = cursor
+		state.Toots = toots
+		state.Notes = notes
+	case pageOlder:
+		state.Cursor.MaxID = cursor.MaxID
+		state.Toots = append(state.Toots, toots...)
+		state.Notes = append(state.Notes, notes...)
+	case pageNewer:
+		if cursor.MinID != "" || cursor.SinceID != "" {
+			state.Cursor.MinID = cursor.MinID
+			state.Cursor.SinceID = cursor.SinceID
+		}
+		state.Toots = append(toots, state.Toots...)
+		state.Notes = append(notes, state.Notes...)
+	}
+
+	// Print the page.
+	if state.Name == "notes" {
+		output.printNotifications(notes)
+	} else {
+		output.printToots(toots)
+	}
+
+	return tootCounter, nil
+}
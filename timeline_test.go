@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/JFFail/GoToot/mastodon"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Function to start a stand-in home timeline with toots 1 to 100, newest first.
+// Each request's query is recorded so the test can check the cursors sent.
+func newTimelineServer(t *testing.T, queries *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		*queries = append(*queries, r.URL.RawQuery)
+
+		limit := 20
+		fmt.Sscan(query.Get("limit"), &limit)
+		newest, oldest := 100, 100-limit+1
+		if query.Get("max_id") != "" {
+			fmt.Sscan(query.Get("max_id"), &newest)
+			newest--
+			oldest = newest - limit + 1
+		}
+		if query.Get("min_id") != "" {
+			fmt.Sscan(query.Get("min_id"), &oldest)
+			oldest++
+			newest = oldest + limit - 1
+		}
+
+		var toots []mastodon.Status
+		for id := newest; id >= oldest; id-- {
+			toots = append(toots, mastodon.Status{ID: fmt.Sprint(id)})
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%v/api/v1/timelines/home?max_id=%v>; rel="next", <http://%v/api/v1/timelines/home?min_id=%v>; rel="prev"`, r.Host, oldest, r.Host, newest))
+		json.NewEncoder(w).Encode(toots)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Function to list the IDs of the toots kept for a timeline.
+func tootIDs(state *timelineState) []string {
+	var ids []string
+	for _, toot := range state.Toots {
+		ids = append(ids, toot.ID)
+	}
+	return ids
+}
+
+func TestShowPageCursors(t *testing.T) {
+	var queries []string
+	server := newTimelineServer(t, &queries)
+	client := mastodon.NewClient(server.URL, "tok")
+	output := outputFormat{Name: formatNDJSON}
+	ctx := context.Background()
+
+	state := &timelineState{Name: "home", Limit: 2}
+	counter, err := showPage(ctx, client, state, pageFirst, 0, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Cursor != (mastodon.Page{MaxID: "99", MinID: "100"}) {
+		t.Errorf("first page cursor is %+v", state.Cursor)
+	}
+
+	// Older pages move MaxID and keep MinID, adding the toots on the end.
+	counter, err = showPage(ctx, client, state, pageOlder, counter, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Cursor != (mastodon.Page{MaxID: "97", MinID: "100"}) {
+		t.Errorf("cursor after older is %+v", state.Cursor)
+	}
+
+	// Newer pages move MinID and keep MaxID, putting the toots first.
+	counter, err = showPage(ctx, client, state, pageNewer, counter, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Cursor != (mastodon.Page{MaxID: "97", MinID: "102"}) {
+		t.Errorf("cursor after newer is %+v", state.Cursor)
+	}
+
+	want := []string{"102", "101", "100", "99", "98", "97"}
+	if fmt.Sprint(tootIDs(state)) != fmt.Sprint(want) || counter != len(want) {
+		t.Errorf("got toots %v with counter %v, want %v", tootIDs(state), counter, want)
+	}
+
+	// Every page is the size the timeline was first shown with.
+	wantQueries := []string{"limit=2", "limit=2&max_id=99", "limit=2&min_id=100"}
+	if fmt.Sprint(queries) != fmt.Sprint(wantQueries) {
+		t.Errorf("got queries %v, want %v", queries, wantQueries)
+	}
+}
+
+func TestShowPageNothingOlder(t *testing.T) {
+	var queries []string
+	server := newTimelineServer(t, &queries)
+	client := mastodon.NewClient(server.URL, "tok")
+
+	// Without a cursor there is nothing to ask for.
+	state := &timelineState{Name: "home", Limit: 2}
+	counter, err := showPage(context.Background(), client, state, pageOlder, 5, outputFormat{Name: formatNDJSON})
+	if err != nil || counter != 5 || len(queries) != 0 {
+		t.Errorf("got counter %v, %v after %v requests", counter, err, len(queries))
+	}
+}